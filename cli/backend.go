@@ -0,0 +1,371 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Backend builds the child process used to execute one gear against one
+// repo. Each backend also owns its own prompt rendering, since a slash
+// command, a markdown file on disk, and a stdin JSON payload all need
+// different framing around the same gear instructions.
+type Backend interface {
+	Name() string
+	BuildCommand(repo Repository, gear string, settings Settings) (*exec.Cmd, error)
+	ValidateEnvironment() error
+}
+
+var backendRegistry = map[string]Backend{}
+
+// RegisterBackend adds (or overrides) a named backend, so downstream users
+// can plug in a proprietary backend without modifying this file.
+func RegisterBackend(name string, b Backend) {
+	backendRegistry[name] = b
+}
+
+func init() {
+	RegisterBackend("claude-code", ClaudeCodeBackend{})
+	RegisterBackend("opencode", OpenCodeBackend{})
+	RegisterBackend("cursor", CursorBackend{})
+	RegisterBackend("aider", AiderBackend{})
+	RegisterBackend("codex", CodexBackend{})
+	RegisterBackend("gemini", GeminiBackend{})
+}
+
+// getBackend resolves a backend by name.
+func getBackend(name string) (Backend, error) {
+	b, ok := backendRegistry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown backend %q", name)
+	}
+	return b, nil
+}
+
+// resolveBackend picks the backend to run: an explicit --backend name takes
+// priority, then backends.yaml's default, then claude-code. Whatever's
+// chosen gets backends.yaml's per-backend env/args layered on afterward.
+func resolveBackend(explicit string) (Backend, error) {
+	cfg, _ := loadBackendsConfig()
+
+	name := explicit
+	if name == "" {
+		name = cfg.Default
+	}
+	if name == "" {
+		name = "claude-code"
+	}
+
+	b, err := getBackend(name)
+	if err != nil {
+		return nil, err
+	}
+	return overriddenBackend{Backend: b, cfg: cfg}, nil
+}
+
+// overriddenBackend wraps a Backend, layering backends.yaml's per-backend
+// env/args onto whatever command the wrapped backend builds.
+type overriddenBackend struct {
+	Backend
+	cfg BackendsConfig
+}
+
+func (b overriddenBackend) BuildCommand(repo Repository, gear string, settings Settings) (*exec.Cmd, error) {
+	cmd, err := b.Backend.BuildCommand(repo, gear, settings)
+	if err != nil {
+		return nil, err
+	}
+	applyBackendOverride(cmd, b.Name(), b.cfg)
+	return cmd, nil
+}
+
+// skillCommands maps gear names to the stackshift plugin's slash commands.
+var skillCommands = map[string]string{
+	"analyze":          "/stackshift:analyze",
+	"reverse-engineer": "/stackshift:reverse-engineer",
+	"create-specs":     "/stackshift:create-specs",
+	"gap-analysis":     "/stackshift:gap-analysis",
+	"complete-spec":    "/stackshift:complete-specs",
+	"implement":        "/stackshift:implement",
+}
+
+// ClaudeCodeBackend drives the StackShift plugin skills via the `claude`
+// CLI's slash commands.
+type ClaudeCodeBackend struct{}
+
+func (ClaudeCodeBackend) Name() string { return "claude-code" }
+
+func (ClaudeCodeBackend) ValidateEnvironment() error {
+	if _, err := exec.LookPath("claude"); err != nil {
+		return fmt.Errorf("claude CLI not found in PATH: %w", err)
+	}
+	return nil
+}
+
+func (ClaudeCodeBackend) BuildCommand(repo Repository, gear string, settings Settings) (*exec.Cmd, error) {
+	skillCommand, exists := skillCommands[gear]
+	if !exists {
+		prompt := fmt.Sprintf("Execute StackShift %s gear for this repository", gear)
+		return claudeCodeCommand(prompt), nil
+	}
+
+	prompt := fmt.Sprintf("Execute the StackShift skill: %s\n\nRepository: %s\nPath: %s\nLanguage: %s\nFramework: %s\n\nSettings:\n- Route: %s\n- Clarification: %s\n- Implementation: %s",
+		skillCommand,
+		repo.Name,
+		repo.Path,
+		repo.Language,
+		repo.Framework,
+		settings.Route,
+		settings.Clarification,
+		settings.Implementation,
+	)
+
+	return claudeCodeCommand(prompt), nil
+}
+
+// claudeCodeCommand runs prompt non-interactively with
+// `--output-format stream-json`, so stdout is the newline-delimited usage
+// event stream tokenCounterWriter scans for Settings.Budgets' MaxTokens
+// enforcement. Without `-p`/`--output-format stream-json`, the child just
+// prints plain text and MaxTokens budgets would never actually preempt it.
+func claudeCodeCommand(prompt string) *exec.Cmd {
+	return exec.Command("claude", "-p", prompt, "--output-format", "stream-json", "--verbose")
+}
+
+// gearPrompts holds the manual, markdown-file instructions for backends
+// that don't understand StackShift's slash commands.
+var gearPrompts = map[string]string{
+	"analyze": `# StackShift Gear 1: Analyze
+
+Please analyze this repository and create an analysis-report.md file containing:
+1. Technology stack analysis
+2. Architecture overview
+3. Dependencies and integrations
+4. Key business logic components
+5. Database schema and data flow`,
+
+	"reverse-engineer": `# StackShift Gear 2: Reverse Engineer
+
+Create comprehensive documentation in docs/reverse-engineering/ including:
+- functional-specification.md
+- data-architecture.md
+- configuration-reference.md
+- api-documentation.md`,
+
+	"create-specs": `# StackShift Gear 3: Create Specs
+
+Based on the analysis, create specifications in docs/specs/ for:
+- Technical requirements
+- API contracts
+- Data models
+- Business rules`,
+
+	"gap-analysis": `# StackShift Gear 4: Gap Analysis
+
+Analyze gaps between current implementation and target stack.
+Create gap-analysis.md documenting:
+- Missing features
+- Architecture differences
+- Migration challenges`,
+
+	"complete-spec": `# StackShift Gear 5: Complete Specifications
+
+Finalize all specifications with:
+- Implementation details
+- Migration strategy
+- Testing requirements
+- Deployment plan`,
+
+	"implement": `# StackShift Gear 6: Implement
+
+Begin implementation based on specifications:
+- Create new components
+- Migrate existing functionality
+- Update tests
+- Document changes`,
+}
+
+// renderMarkdownPrompt builds the shared markdown prompt body used by the
+// manual-style backends (OpenCode, Cursor, Aider, Codex, Gemini, Exec).
+func renderMarkdownPrompt(gear string, repo Repository, settings Settings) string {
+	basePrompt := gearPrompts[gear]
+	if basePrompt == "" {
+		basePrompt = fmt.Sprintf("# StackShift Gear: %s\n\nExecute the %s gear for this repository.", gear, gear)
+	}
+
+	return fmt.Sprintf(`%s
+
+Repository: %s
+Path: %s
+Language: %s
+Framework: %s
+
+Settings:
+- Route: %s
+- Clarification Strategy: %s
+- Implementation Scope: %s
+
+Please execute this gear and update .stackshift-state.json when complete.`,
+		basePrompt,
+		repo.Name,
+		repo.Path,
+		repo.Language,
+		repo.Framework,
+		settings.Route,
+		settings.Clarification,
+		settings.Implementation,
+	)
+}
+
+// writePromptFile renders the markdown prompt for a gear to a temp file and
+// returns its path, for backends that take a file argument.
+func writePromptFile(repo Repository, gear string, settings Settings) (string, error) {
+	content := renderMarkdownPrompt(gear, repo, settings)
+	path := filepath.Join(os.TempDir(), fmt.Sprintf("stackshift_%s_%s.md", repo.Name, gear))
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("writing prompt file: %w", err)
+	}
+	return path, nil
+}
+
+// OpenCodeBackend opens the repo and a generated prompt file in VSCode (for
+// use with GitHub Copilot), falling back to printing instructions if the
+// `code` CLI isn't installed.
+type OpenCodeBackend struct{}
+
+func (OpenCodeBackend) Name() string { return "opencode" }
+
+func (OpenCodeBackend) ValidateEnvironment() error {
+	if _, err := exec.LookPath("code"); err != nil {
+		return fmt.Errorf("code CLI not found in PATH: %w", err)
+	}
+	return nil
+}
+
+func (OpenCodeBackend) BuildCommand(repo Repository, gear string, settings Settings) (*exec.Cmd, error) {
+	tmpFile, err := writePromptFile(repo, gear, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := exec.LookPath("code"); err == nil {
+		return exec.Command("code", repo.Path, tmpFile), nil
+	}
+
+	return exec.Command("echo", fmt.Sprintf("Please run StackShift %s gear for %s manually", gear, repo.Name)), nil
+}
+
+// CursorBackend drives the `cursor-agent` CLI with the rendered gear prompt
+// piped in on stdin.
+type CursorBackend struct{}
+
+func (CursorBackend) Name() string { return "cursor" }
+
+func (CursorBackend) ValidateEnvironment() error {
+	if _, err := exec.LookPath("cursor-agent"); err != nil {
+		return fmt.Errorf("cursor-agent CLI not found in PATH: %w", err)
+	}
+	return nil
+}
+
+func (CursorBackend) BuildCommand(repo Repository, gear string, settings Settings) (*exec.Cmd, error) {
+	prompt := renderMarkdownPrompt(gear, repo, settings)
+	cmd := exec.Command("cursor-agent", "-p", "--force")
+	cmd.Stdin = strings.NewReader(prompt)
+	return cmd, nil
+}
+
+// AiderBackend drives the `aider` CLI's --message flag with the rendered
+// gear prompt.
+type AiderBackend struct{}
+
+func (AiderBackend) Name() string { return "aider" }
+
+func (AiderBackend) ValidateEnvironment() error {
+	if _, err := exec.LookPath("aider"); err != nil {
+		return fmt.Errorf("aider CLI not found in PATH: %w", err)
+	}
+	return nil
+}
+
+func (AiderBackend) BuildCommand(repo Repository, gear string, settings Settings) (*exec.Cmd, error) {
+	prompt := renderMarkdownPrompt(gear, repo, settings)
+	return exec.Command("aider", "--yes", "--message", prompt), nil
+}
+
+// CodexBackend drives OpenAI's `codex` CLI in non-interactive exec mode,
+// with the rendered gear prompt as its final argument.
+type CodexBackend struct{}
+
+func (CodexBackend) Name() string { return "codex" }
+
+func (CodexBackend) ValidateEnvironment() error {
+	if _, err := exec.LookPath("codex"); err != nil {
+		return fmt.Errorf("codex CLI not found in PATH: %w", err)
+	}
+	return nil
+}
+
+func (CodexBackend) BuildCommand(repo Repository, gear string, settings Settings) (*exec.Cmd, error) {
+	prompt := renderMarkdownPrompt(gear, repo, settings)
+	return exec.Command("codex", "exec", "--full-auto", prompt), nil
+}
+
+// GeminiBackend drives Google's `gemini` CLI with the rendered gear prompt
+// passed via its -p flag.
+type GeminiBackend struct{}
+
+func (GeminiBackend) Name() string { return "gemini" }
+
+func (GeminiBackend) ValidateEnvironment() error {
+	if _, err := exec.LookPath("gemini"); err != nil {
+		return fmt.Errorf("gemini CLI not found in PATH: %w", err)
+	}
+	return nil
+}
+
+func (GeminiBackend) BuildCommand(repo Repository, gear string, settings Settings) (*exec.Cmd, error) {
+	prompt := renderMarkdownPrompt(gear, repo, settings)
+	return exec.Command("gemini", "-p", prompt, "--yolo"), nil
+}
+
+// ExecBackend runs an arbitrary user-supplied command template, with
+// {{.Repo.Path}}, {{.Gear}}, and {{.PromptFile}} placeholders substituted
+// before the command is handed to the shell. It is not registered by
+// default: it can only be selected by name once registered via
+// RegisterBackend with its Template set.
+type ExecBackend struct {
+	Template string
+}
+
+type execTemplateData struct {
+	Repo       Repository
+	Gear       string
+	PromptFile string
+}
+
+func (ExecBackend) Name() string { return "exec" }
+
+func (ExecBackend) ValidateEnvironment() error { return nil }
+
+func (b ExecBackend) BuildCommand(repo Repository, gear string, settings Settings) (*exec.Cmd, error) {
+	promptFile, err := writePromptFile(repo, gear, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New("exec-backend").Parse(b.Template)
+	if err != nil {
+		return nil, fmt.Errorf("parsing exec backend template: %w", err)
+	}
+
+	var rendered strings.Builder
+	if err := tmpl.Execute(&rendered, execTemplateData{Repo: repo, Gear: gear, PromptFile: promptFile}); err != nil {
+		return nil, fmt.Errorf("rendering exec backend template: %w", err)
+	}
+
+	return exec.Command("sh", "-c", rendered.String()), nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BackendOverride is one backend's entry in backends.yaml: extra
+// environment variables and CLI args layered onto whatever the Go
+// implementation builds, without needing a code change per backend.
+type BackendOverride struct {
+	Env  map[string]string `yaml:"env"`
+	Args []string          `yaml:"args"`
+}
+
+// BackendsConfig is the user's ~/.stackshift/backends.yaml: which backend
+// to use when --backend isn't passed, plus per-backend overrides.
+type BackendsConfig struct {
+	Default  string                     `yaml:"default"`
+	Backends map[string]BackendOverride `yaml:"backends"`
+}
+
+func backendsConfigPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".stackshift", "backends.yaml"), nil
+}
+
+// loadBackendsConfig reads backends.yaml, returning a zero-valued config
+// (not an error) if it doesn't exist yet.
+func loadBackendsConfig() (BackendsConfig, error) {
+	path, err := backendsConfigPath()
+	if err != nil {
+		return BackendsConfig{}, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return BackendsConfig{}, nil
+		}
+		return BackendsConfig{}, err
+	}
+
+	var cfg BackendsConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return BackendsConfig{}, err
+	}
+	return cfg, nil
+}
+
+// applyBackendOverride layers backends.yaml's env/args for name onto cmd, so
+// a user can add e.g. a model flag or an API key without forking a backend.
+func applyBackendOverride(cmd *exec.Cmd, name string, cfg BackendsConfig) {
+	override, ok := cfg.Backends[name]
+	if !ok {
+		return
+	}
+
+	if len(override.Args) > 0 {
+		cmd.Args = append(cmd.Args, override.Args...)
+	}
+	if len(override.Env) > 0 {
+		env := cmd.Env
+		if env == nil {
+			env = os.Environ()
+		}
+		for k, v := range override.Env {
+			env = append(env, k+"="+v)
+		}
+		cmd.Env = env
+	}
+}
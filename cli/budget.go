@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// BudgetPolicy controls what happens once a gear's Budget is exceeded.
+type BudgetPolicy string
+
+const (
+	BudgetKill     BudgetPolicy = "kill"
+	BudgetWarn     BudgetPolicy = "warn"
+	BudgetContinue BudgetPolicy = "continue"
+)
+
+// Budget caps how long a gear may run and, for backends that emit a token
+// count, how many tokens it may burn before being preempted. MaxRetries and
+// RetryBackoff additionally control how many times a failed attempt (a
+// non-zero exit or a validation failure) is retried before the gear is
+// reported as failed.
+type Budget struct {
+	MaxDuration  time.Duration `json:"maxDuration"`
+	MaxTokens    int           `json:"maxTokens"`
+	OnExceed     BudgetPolicy  `json:"onExceed"`
+	MaxRetries   int           `json:"maxRetries"`
+	RetryBackoff time.Duration `json:"retryBackoff"`
+}
+
+func (b Budget) policy() BudgetPolicy {
+	if b.OnExceed == "" {
+		return BudgetKill
+	}
+	return b.OnExceed
+}
+
+// retryBackoff returns the base delay before the next retry attempt,
+// doubled per attempt by runGear. A Budget with no explicit RetryBackoff
+// still gets a sane default once MaxRetries makes retries possible.
+func (b Budget) retryBackoff() time.Duration {
+	if b.RetryBackoff > 0 {
+		return b.RetryBackoff
+	}
+	return 5 * time.Second
+}
+
+// resolveBudget looks up a gear's budget, preferring a repo-local
+// .stackshift-budget.json override over the orchestrator-wide default from
+// Settings.Budgets.
+func (o *Orchestrator) resolveBudget(repo Repository, gearName string) Budget {
+	if repoBudgets, err := loadRepoBudgets(repo.Path); err == nil {
+		if b, ok := repoBudgets[gearName]; ok {
+			return b
+		}
+	}
+	return o.settings.Budgets[gearName]
+}
+
+// loadRepoBudgets reads a repo's .stackshift-budget.json, which follows the
+// same shape as Settings.Budgets (gear name -> Budget).
+func loadRepoBudgets(repoPath string) (map[string]Budget, error) {
+	data, err := os.ReadFile(filepath.Join(repoPath, ".stackshift-budget.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var budgets map[string]Budget
+	if err := json.Unmarshal(data, &budgets); err != nil {
+		return nil, err
+	}
+	return budgets, nil
+}
+
+// tokenCounterWriter scans a Claude Code `--output-format stream-json`
+// stream for usage events and signals exceeded once the running total
+// crosses limit. A limit <= 0 disables tracking entirely.
+type tokenCounterWriter struct {
+	limit    int
+	exceeded chan struct{}
+
+	mu      sync.Mutex
+	total   int
+	closed  bool
+	pending []byte
+}
+
+func newTokenCounterWriter(limit int) *tokenCounterWriter {
+	return &tokenCounterWriter{limit: limit, exceeded: make(chan struct{})}
+}
+
+type streamJSONUsageEvent struct {
+	Usage struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+func (w *tokenCounterWriter) Write(p []byte) (int, error) {
+	if w.limit <= 0 {
+		return len(p), nil
+	}
+
+	// A single OS read rarely lines up with a stream-json event boundary, so
+	// buffer any trailing partial line and prepend it to the next Write
+	// instead of assuming this call's p is itself newline-aligned. Without
+	// this, a usage event split across two Write calls fails to unmarshal on
+	// both halves and is silently dropped, undercounting the budget.
+	w.mu.Lock()
+	w.pending = append(w.pending, p...)
+	var lines [][]byte
+	for {
+		idx := bytes.IndexByte(w.pending, '\n')
+		if idx < 0 {
+			break
+		}
+		lines = append(lines, append([]byte(nil), w.pending[:idx]...))
+		w.pending = w.pending[idx+1:]
+	}
+	w.mu.Unlock()
+
+	for _, line := range lines {
+		if len(line) == 0 {
+			continue
+		}
+
+		var event streamJSONUsageEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			continue
+		}
+
+		w.mu.Lock()
+		w.total += event.Usage.InputTokens + event.Usage.OutputTokens
+		over := w.total >= w.limit
+		alreadyClosed := w.closed
+		if over && !alreadyClosed {
+			w.closed = true
+		}
+		w.mu.Unlock()
+
+		if over && !alreadyClosed {
+			close(w.exceeded)
+		}
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// gearArtifacts lists the paths (relative to a repo's root) each gear is
+// expected to produce. These are exactly what the cache snapshots after a
+// successful run and restores on a cache hit. A gear with no entry writes
+// too broadly across the repo to snapshot safely (e.g. "implement") and
+// always runs uncached.
+var gearArtifacts = map[string][]string{
+	"analyze":          {"analysis-report.md"},
+	"reverse-engineer": {"docs/reverse-engineering"},
+	"create-specs":     {"docs/specs"},
+	"gap-analysis":     {"gap-analysis.md"},
+	"complete-spec":    {"docs/specs"},
+}
+
+// hashGearInputs computes a stable content hash over everything that
+// determines a gear's output: the repo's own files (filtered by
+// .stackshiftignore), the gear's rendered prompt, the Settings, and which
+// AI backend will run it. Two invocations with the same hash are expected
+// to produce the same result, so it also serves as the cache key.
+func hashGearInputs(repo Repository, gearName string, settings Settings, backendName string) (string, error) {
+	h := sha256.New()
+
+	if err := hashRepoContents(h, repo.Path); err != nil {
+		return "", err
+	}
+
+	io.WriteString(h, renderMarkdownPrompt(gearName, repo, settings))
+
+	settingsJSON, err := json.Marshal(settings)
+	if err != nil {
+		return "", err
+	}
+	h.Write(settingsJSON)
+
+	io.WriteString(h, backendName)
+
+	return hex.EncodeToString(h.Sum(nil))[:16], nil
+}
+
+// hashRepoContents feeds every non-ignored file under repoPath into h, in a
+// deterministic (sorted by relative path) order so the result only depends
+// on file contents, never walk order or timestamps.
+func hashRepoContents(h io.Writer, repoPath string) error {
+	ignore := loadStackshiftIgnore(repoPath)
+
+	var files []string
+	err := filepath.WalkDir(repoPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, relErr := filepath.Rel(repoPath, path)
+		if relErr != nil || rel == "." {
+			return nil
+		}
+
+		if d.IsDir() {
+			if d.Name() == ".git" || ignore.matches(rel+"/") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if ignore.matches(rel) {
+			return nil
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.Strings(files)
+	for _, rel := range files {
+		data, err := os.ReadFile(filepath.Join(repoPath, rel))
+		if err != nil {
+			continue
+		}
+		io.WriteString(h, rel)
+		h.Write(data)
+	}
+	return nil
+}
+
+// stackshiftIgnore is a minimal .gitignore-style filter: one shell glob
+// pattern per line (matched via filepath.Match against both the full
+// relative path and the base name), blank lines and '#' comments skipped.
+// It deliberately doesn't implement full gitignore syntax (negation, '**',
+// anchoring) — just enough to keep build output and dependencies out of
+// the cache hash.
+type stackshiftIgnore struct {
+	patterns []string
+}
+
+// loadStackshiftIgnore reads repoPath/.stackshiftignore, returning an empty
+// (match-nothing) filter if it doesn't exist.
+func loadStackshiftIgnore(repoPath string) stackshiftIgnore {
+	data, err := os.ReadFile(filepath.Join(repoPath, ".stackshiftignore"))
+	if err != nil {
+		return stackshiftIgnore{}
+	}
+
+	var patterns []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return stackshiftIgnore{patterns: patterns}
+}
+
+func (ig stackshiftIgnore) matches(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range ig.patterns {
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, filepath.Base(rel)); ok {
+			return true
+		}
+		if strings.HasPrefix(rel, strings.TrimSuffix(pattern, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheablePaths returns the artifact paths a gear produces, plus the state
+// file every gear updates, or nil if the gear has no cacheable artifacts.
+func cacheablePaths(gearName string) []string {
+	artifacts, ok := gearArtifacts[gearName]
+	if !ok || len(artifacts) == 0 {
+		return nil
+	}
+
+	paths := make([]string, 0, len(artifacts)+1)
+	paths = append(paths, artifacts...)
+	paths = append(paths, ".stackshift-state.json")
+	return paths
+}
+
+// restoreFromCache copies a prior successful run's artifacts for (repo,
+// gearName) out of <cacheDir>/<hash>/ back into the repo, so a cache hit
+// can skip the exec entirely. Returns false if there's no entry, the gear
+// has no cacheable artifacts, or restoring fails partway through.
+func (o *Orchestrator) restoreFromCache(repo Repository, gearName, hash string) (bool, string) {
+	paths := cacheablePaths(gearName)
+	if paths == nil {
+		return false, ""
+	}
+
+	entryDir := filepath.Join(o.cacheDir, hash)
+	if _, err := os.Stat(entryDir); err != nil {
+		return false, ""
+	}
+
+	for _, path := range paths {
+		src := filepath.Join(entryDir, path)
+		if _, err := os.Stat(src); err != nil {
+			continue
+		}
+		if err := copyPath(src, filepath.Join(repo.Path, path)); err != nil {
+			return false, ""
+		}
+	}
+
+	return true, fmt.Sprintf("Restored from cache (%s)", hash)
+}
+
+// populateCache snapshots (repo, gearName)'s produced artifacts into
+// <cacheDir>/<hash>/ after a successful run, atomically via a temp
+// directory plus rename so a crash mid-copy never leaves a half-written
+// entry that a later run could mistake for a hit.
+func (o *Orchestrator) populateCache(repo Repository, gearName, hash string) error {
+	paths := cacheablePaths(gearName)
+	if paths == nil {
+		return nil
+	}
+
+	entryDir := filepath.Join(o.cacheDir, hash)
+	if _, err := os.Stat(entryDir); err == nil {
+		return nil // already populated, e.g. by another repo that hashed the same
+	}
+
+	tmpDir := entryDir + ".tmp-" + repo.Name
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		src := filepath.Join(repo.Path, path)
+		if _, err := os.Stat(src); err != nil {
+			continue
+		}
+		if err := copyPath(src, filepath.Join(tmpDir, path)); err != nil {
+			os.RemoveAll(tmpDir)
+			return err
+		}
+	}
+
+	return os.Rename(tmpDir, entryDir)
+}
+
+// copyPath copies src to dst, recursing into directories and creating
+// parent directories as needed.
+func copyPath(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dst, 0755); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := copyPath(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, info.Mode())
+}
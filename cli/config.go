@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/pflag"
+)
+
+// Config is the user's persisted defaults at ~/.config/stackshift/config.toml,
+// so Settings edits from the TUI survive restarts instead of resetting to
+// the hardcoded defaults every launch.
+type Config struct {
+	Route          string   `toml:"route"`
+	Transmission   string   `toml:"transmission"`
+	Clarification  string   `toml:"clarification"`
+	Implementation string   `toml:"implementation"`
+	ParallelLimit  int      `toml:"parallel_limit"`
+	Backend        string   `toml:"backend"`
+	SearchPaths    []string `toml:"search_paths"`
+	ExcludedGlobs  []string `toml:"excluded_globs"`
+}
+
+func configPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".config", "stackshift", "config.toml"), nil
+}
+
+// loadConfig reads the global config file, returning a zero-valued Config
+// (not an error) if it doesn't exist yet.
+func loadConfig() (Config, error) {
+	path, err := configPath()
+	if err != nil {
+		return Config{}, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Config{}, nil
+		}
+		return Config{}, err
+	}
+
+	var cfg Config
+	if _, err := toml.Decode(string(data), &cfg); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// saveConfig atomically writes cfg to the global config file.
+func saveConfig(cfg Config) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(cfg); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// applyConfigDefaults fills in any flag that the user didn't pass explicitly
+// from the global config, so `--route` etc. still override a saved default.
+func applyConfigDefaults(flags *pflag.FlagSet) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return
+	}
+
+	setString := func(name string, dst *string, value string) {
+		if value != "" && !flags.Changed(name) {
+			*dst = value
+		}
+	}
+
+	setString("route", &flagRoute, cfg.Route)
+	setString("transmission", &flagTransmission, cfg.Transmission)
+	setString("clarification", &flagClarification, cfg.Clarification)
+	setString("implementation", &flagImplementation, cfg.Implementation)
+	setString("backend", &flagBackend, cfg.Backend)
+
+	if cfg.ParallelLimit > 0 && !flags.Changed("parallel") {
+		flagParallel = cfg.ParallelLimit
+	}
+	if flagPath == "" && len(cfg.SearchPaths) > 0 {
+		flagPath = cfg.SearchPaths[0]
+	}
+	if len(cfg.ExcludedGlobs) > 0 && !flags.Changed("exclude") {
+		flagExclude = strings.Join(cfg.ExcludedGlobs, ",")
+	}
+}
+
+// persistSettingsConfig saves the TUI's current Settings choices as the new
+// global defaults, preserving whatever search paths/excluded globs are
+// already on disk since the Settings screen doesn't edit those.
+func (m model) persistSettingsConfig() {
+	cfg, err := loadConfig()
+	if err != nil {
+		cfg = Config{}
+	}
+
+	cfg.Route = m.route
+	cfg.Transmission = m.transmission
+	cfg.Clarification = m.clarification
+	cfg.Implementation = m.implementation
+	cfg.ParallelLimit = m.parallelLimit
+	if m.useClaudeCode {
+		cfg.Backend = "claude-code"
+	} else {
+		cfg.Backend = "opencode"
+	}
+
+	saveConfig(cfg)
+}
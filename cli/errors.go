@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValidationReason distinguishes why validateGear rejected a gear, so
+// callers can decide whether a retry is even worth attempting (a malformed
+// state file might be worth a retry; a gear genuinely not yet done isn't).
+type ValidationReason int
+
+const (
+	ValidationMissingStateFile ValidationReason = iota
+	ValidationMalformedStateFile
+	ValidationGearNotComplete
+)
+
+func (r ValidationReason) String() string {
+	switch r {
+	case ValidationMissingStateFile:
+		return "missing state file"
+	case ValidationMalformedStateFile:
+		return "malformed state file"
+	case ValidationGearNotComplete:
+		return "gear not in completedSteps"
+	default:
+		return "unknown validation failure"
+	}
+}
+
+// ValidationError is returned by validateGear when a gear's completion
+// can't be confirmed from .stackshift-state.json.
+type ValidationError struct {
+	Reason ValidationReason
+	Detail string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Detail == "" {
+		return e.Reason.String()
+	}
+	return fmt.Sprintf("%s: %s", e.Reason, e.Detail)
+}
+
+// GearFailure captures everything needed to diagnose one failed gear.
+type GearFailure struct {
+	Repo       string
+	Gear       int
+	ExitCode   int
+	StderrTail string
+	Reason     string
+}
+
+func (f *GearFailure) Error() string {
+	return fmt.Sprintf("%s gear %d (%s): %s", f.Repo, f.Gear, gearNames[f.Gear], f.Reason)
+}
+
+// BatchError aggregates every gear failure from one Orchestrator.Run,
+// modeled on cli.NewMultiError: it preserves each failure's structured
+// fields while still behaving like a single error for errors.Is/As callers.
+type BatchError struct {
+	Failures []*GearFailure
+}
+
+func (b *BatchError) Error() string {
+	if len(b.Failures) == 0 {
+		return "no failures"
+	}
+	lines := make([]string, len(b.Failures))
+	for i, f := range b.Failures {
+		lines[i] = f.Error()
+	}
+	return fmt.Sprintf("%d gear(s) failed:\n%s", len(b.Failures), strings.Join(lines, "\n"))
+}
+
+func (b *BatchError) Unwrap() []error {
+	errs := make([]error, len(b.Failures))
+	for i, f := range b.Failures {
+		errs[i] = f
+	}
+	return errs
+}
+
+// GroupedSummary renders failures grouped by gear, one line per gear, e.g.
+// "3 repo(s) failed at gear 2 (reverse-engineer): repo-a, repo-b, repo-c",
+// for a CLI summary instead of burying failures in per-line logs.
+func (b *BatchError) GroupedSummary() string {
+	byGear := map[int][]string{}
+	var gearOrder []int
+	for _, f := range b.Failures {
+		if _, seen := byGear[f.Gear]; !seen {
+			gearOrder = append(gearOrder, f.Gear)
+		}
+		byGear[f.Gear] = append(byGear[f.Gear], f.Repo)
+	}
+	sort.Ints(gearOrder)
+
+	parts := make([]string, 0, len(gearOrder))
+	for _, gear := range gearOrder {
+		repos := byGear[gear]
+		parts = append(parts, fmt.Sprintf("%d repo(s) failed at gear %d (%s): %s",
+			len(repos), gear, gearNames[gear], strings.Join(repos, ", ")))
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sahilm/fuzzy"
+)
+
+var matchHighlightStyle = lipgloss.NewStyle().
+	Bold(true).
+	Foreground(lipgloss.Color("214"))
+
+// filteredRepo pairs a Repository with the rune indexes (into its fuzzy
+// search string) that matched the current filter query, so the UI can
+// highlight what the user typed.
+type filteredRepo struct {
+	repo    Repository
+	matches []int
+}
+
+// nameMatches returns the subset of matches that fall within the repo's
+// Name, since searchable() puts Name first and the match indexes are
+// positions into that combined string.
+func (fr filteredRepo) nameMatches(nameLen int) []int {
+	var out []int
+	for _, idx := range fr.matches {
+		if idx < nameLen {
+			out = append(out, idx)
+		}
+	}
+	return out
+}
+
+// searchable builds the string a repo is fuzzy-matched against: name,
+// language, and framework, so "react" matches a framework as readily as a
+// repo named "react-dashboard".
+func searchable(r Repository) string {
+	return fmt.Sprintf("%s %s %s", r.Name, r.Language, r.Framework)
+}
+
+// filterRepos narrows repos to those whose searchable() string fuzzy-matches
+// query, sorted by match score. An empty query returns every repo unscored.
+func filterRepos(repos []Repository, query string) []filteredRepo {
+	if query == "" {
+		out := make([]filteredRepo, len(repos))
+		for i, r := range repos {
+			out[i] = filteredRepo{repo: r}
+		}
+		return out
+	}
+
+	searchables := make([]string, len(repos))
+	for i, r := range repos {
+		searchables[i] = searchable(r)
+	}
+
+	matches := fuzzy.Find(query, searchables)
+	out := make([]filteredRepo, len(matches))
+	for i, match := range matches {
+		out[i] = filteredRepo{repo: repos[match.Index], matches: match.MatchedIndexes}
+	}
+	return out
+}
+
+// highlightMatches renders s with the runes at the given indexes styled, so
+// a filter match stands out in the rendered repo name.
+func highlightMatches(s string, matches []int) string {
+	if len(matches) == 0 {
+		return s
+	}
+
+	matched := make(map[int]bool, len(matches))
+	for _, idx := range matches {
+		matched[idx] = true
+	}
+
+	var b strings.Builder
+	for i, r := range s {
+		if matched[i] {
+			b.WriteString(matchHighlightStyle.Render(string(r)))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/pflag"
+)
+
+// runHeadless drives the orchestrator directly, with no Bubble Tea program,
+// so stackshift can run unattended in a CI pipeline or shell script. It
+// mirrors startExecution's orchestrator setup but reads straight from the
+// package-level flag vars instead of a model. flags is rootCmd's resolved
+// FlagSet, passed in by the caller rather than read back off rootCmd itself,
+// since rootCmd's own RunE is what calls runHeadless — referencing rootCmd
+// from inside a function it transitively calls from its own initializer is
+// an initialization cycle.
+func runHeadless(flags *pflag.FlagSet) error {
+	searchPath := resolveSearchPath()
+	repos := discoverRepositories(searchPath)
+
+	if flagSelect != "" {
+		repos = selectRepos(repos, strings.Split(flagSelect, ","))
+	}
+
+	if len(repos) == 0 {
+		return fmt.Errorf("no repositories matched under %s", searchPath)
+	}
+
+	settings := Settings{
+		Route:          flagRoute,
+		Transmission:   flagTransmission,
+		Clarification:  flagClarification,
+		Implementation: flagImplementation,
+		Budgets:        activeProfile.Budgets,
+	}
+
+	useClaudeCode := flagBackend == "" || flagBackend == "claude-code"
+
+	var orchestrator *Orchestrator
+	if flagResume != "" {
+		var err error
+		orchestrator, err = NewOrchestratorResume(flagResume, repos, settings, flagParallel, useClaudeCode)
+		if err != nil {
+			return fmt.Errorf("resume failed: %w", err)
+		}
+	} else {
+		orchestrator = NewOrchestrator(repos, settings, flagParallel, useClaudeCode)
+	}
+
+	if flagTaskGraph != "" {
+		orchestrator.SetTaskGraph(flagTaskGraph)
+	}
+	if flagExclude != "" {
+		orchestrator.SetExcludedGears(strings.Split(flagExclude, ","))
+	}
+	// An empty flagBackend resolves to backends.yaml's default, then claude-code.
+	if err := orchestrator.SetBackend(flagBackend); err != nil {
+		return fmt.Errorf("backend error: %w", err)
+	}
+	orchestrator.SetSearchPath(searchPath)
+	if flagSpaceURL != "" {
+		orchestrator.SetSpaceURL(flagSpaceURL, flagSpaceToken)
+	}
+	orchestrator.SetNoReport(flagNoReport)
+	if flagCacheDir != "" {
+		orchestrator.SetCacheDir(flagCacheDir)
+	}
+	orchestrator.SetNoCache(flagNoCache)
+	orchestrator.SetForce(flagForce)
+	orchestrator.SetRestart(flagRestart)
+	if flagOnly != "" {
+		orchestrator.SetOnlyGears(strings.Split(flagOnly, ","))
+	}
+	orchestrator.SetFlagOverrides(flags.Changed("transmission"), flags.Changed("implementation"))
+	orchestrator.SetProfile(activeProfile)
+	orchestrator.AddProgressSink(&StdoutProgressSink{JSON: flagJSON})
+
+	// A headless run has no TUI to intercept Ctrl-C as a keystroke, so wire
+	// SIGINT/SIGTERM straight to KillAll: in-flight gears get a grace period
+	// to finish before being hard-killed, process group and all.
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		if _, ok := <-sigCh; ok {
+			orchestrator.KillAll()
+		}
+	}()
+
+	_, err := orchestrator.Run()
+	return err
+}
+
+// selectRepos narrows repos to those whose Name matches at least one of
+// patterns (shell-style globs, as accepted by filepath.Match).
+func selectRepos(repos []Repository, patterns []string) []Repository {
+	var out []Repository
+	for _, repo := range repos {
+		for _, pattern := range patterns {
+			if matched, _ := filepath.Match(strings.TrimSpace(pattern), repo.Name); matched {
+				out = append(out, repo)
+				break
+			}
+		}
+	}
+	return out
+}
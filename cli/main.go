@@ -4,10 +4,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/jschulte/stackshift/pkg/detect"
+	"github.com/spf13/cobra"
 )
 
 const (
@@ -22,7 +28,6 @@ type model struct {
 	// State
 	mode         int
 	cursor       int
-	scrollOffset int
 	width        int
 	height       int
 	spinnerFrame int
@@ -30,34 +35,47 @@ type model struct {
 	// Data
 	repos         []Repository
 	selectedRepos map[string]bool
+	searchPath    string
+
+	// Filtering (SelectMode)
+	filtering   bool
+	filterInput textinput.Model
+	viewport    viewport.Model
+	filtered    []filteredRepo
 
 	// Settings
-	route            string // "greenfield" or "brownfield"
-	transmission     string // "manual" or "cruise-control"
-	clarification    string // "prompt", "defer", "skip"
-	implementation   string // "none", "p0", "p0_p1", "all"
-	targetStack      string // For greenfield
-	parallelLimit    int
-	useClaudeCode    bool   // true = claude code, false = opencode
-	settingsCursor   int
+	route              string // "greenfield" or "brownfield"
+	transmission       string // "manual" or "cruise-control"
+	clarification      string // "prompt", "defer", "skip"
+	implementation     string // "none", "p0", "p0_p1", "all"
+	targetStack        string // For greenfield
+	targetStackInput   textinput.Model
+	editingTargetStack bool
+	parallelLimit      int
+	useClaudeCode      bool // true = claude code, false = opencode
+	settingsCursor     int
 
 	// Execution
-	orchestrator     *Orchestrator
-	runningTasks     []Task
-	logs             []string
-	executionDone    bool
+	orchestrator  *Orchestrator
+	runningTasks  []Task
+	logs          []string
+	executionDone bool
 
 	// Results
-	results []GearResult
+	results        []GearResult
+	failureSummary string
 }
 
 type Repository struct {
-	Name         string
-	Path         string
-	Language     string
-	Framework    string
-	CurrentGear  int    // 0-6
-	Status       string // "not_started", "in_progress", "complete", "failed"
+	Name           string
+	Path           string
+	Language       string
+	Framework      string
+	PackageManager string
+	Monorepo       bool
+	Workspaces     []string
+	CurrentGear    int    // 0-6
+	Status         string // "not_started", "in_progress", "complete", "failed"
 }
 
 type Task struct {
@@ -69,11 +87,14 @@ type Task struct {
 }
 
 type GearResult struct {
-	Repo    string
-	Gear    int
-	Success bool
-	Message string
-	Files   []string
+	Repo       string
+	Gear       int
+	Success    bool
+	Message    string
+	Files      []string
+	ExitCode   int
+	StderrTail string
+	Cached     bool
 }
 
 // Message types for execution updates
@@ -87,6 +108,7 @@ type taskUpdateMsg struct {
 
 type executionCompleteMsg struct {
 	results []GearResult
+	summary string // grouped failure summary, e.g. "3 repo(s) failed at gear 2 (reverse-engineer): ..."
 }
 
 type logMsg struct {
@@ -113,33 +135,51 @@ var (
 
 func initialModel() model {
 	// Auto-discover repositories
-	repos := discoverRepositories()
+	searchPath := resolveSearchPath()
+	repos := discoverRepositories(searchPath)
+
+	filterInput := textinput.New()
+	filterInput.Placeholder = "filter by name, language, or framework..."
+	filterInput.Prompt = "/ "
+
+	targetStackInput := textinput.New()
+	targetStackInput.Placeholder = "e.g. Next.js + tRPC + Postgres"
+	targetStackInput.Prompt = "> "
 
 	return model{
-		mode:           SelectMode,
-		repos:          repos,
-		selectedRepos:  make(map[string]bool),
-		route:          "brownfield",
-		transmission:   "cruise-control",
-		clarification:  "defer",
-		implementation: "p0",
-		parallelLimit:  3,
-		useClaudeCode:  true,
-		logs:           []string{},
+		mode:             SelectMode,
+		repos:            repos,
+		selectedRepos:    make(map[string]bool),
+		searchPath:       searchPath,
+		filterInput:      filterInput,
+		viewport:         viewport.New(0, 0),
+		filtered:         filterRepos(repos, ""),
+		route:            flagRoute,
+		transmission:     flagTransmission,
+		clarification:    flagClarification,
+		implementation:   flagImplementation,
+		targetStackInput: targetStackInput,
+		parallelLimit:    flagParallel,
+		useClaudeCode:    flagBackend == "" || flagBackend == "claude-code",
+		logs:             []string{},
 	}
 }
 
-func discoverRepositories() []Repository {
-	var repos []Repository
+// resolveSearchPath returns the root directory to scan for repos: --path
+// (or the positional argument it's populated from), or ~/git by default.
+func resolveSearchPath() string {
+	if flagPath != "" {
+		return flagPath
+	}
 
-	// Default search path
 	homeDir, _ := os.UserHomeDir()
 	searchPath := filepath.Join(homeDir, "git")
 
-	// Check if user provided custom path
-	if len(os.Args) > 1 && os.Args[1] != "" {
-		searchPath = os.Args[1]
-	}
+	return searchPath
+}
+
+func discoverRepositories(searchPath string) []Repository {
+	var repos []Repository
 
 	// Recursively find all .git directories
 	filepath.Walk(searchPath, func(path string, info os.FileInfo, err error) error {
@@ -151,19 +191,22 @@ func discoverRepositories() []Repository {
 			repoPath := filepath.Dir(path)
 			repoName := filepath.Base(repoPath)
 
-			// Detect language/framework
-			lang, framework := detectTechStack(repoPath)
+			// Detect language/framework/package manager
+			stack := detect.Detect(repoPath)
 
 			// Check current progress
 			currentGear, status := checkProgress(repoPath)
 
 			repos = append(repos, Repository{
-				Name:        repoName,
-				Path:        repoPath,
-				Language:    lang,
-				Framework:   framework,
-				CurrentGear: currentGear,
-				Status:      status,
+				Name:           repoName,
+				Path:           repoPath,
+				Language:       stack.Language,
+				Framework:      stack.Framework,
+				PackageManager: stack.PackageManager,
+				Monorepo:       stack.Monorepo,
+				Workspaces:     stack.Workspaces,
+				CurrentGear:    currentGear,
+				Status:         status,
 			})
 		}
 
@@ -173,45 +216,8 @@ func discoverRepositories() []Repository {
 	return repos
 }
 
-func detectTechStack(repoPath string) (string, string) {
-	// Check for package.json (Node.js)
-	if _, err := os.Stat(filepath.Join(repoPath, "package.json")); err == nil {
-		// Read package.json to detect framework
-		// Simplified for now
-		return "JavaScript/TypeScript", "Unknown"
-	}
-
-	// Check for requirements.txt (Python)
-	if _, err := os.Stat(filepath.Join(repoPath, "requirements.txt")); err == nil {
-		return "Python", "Unknown"
-	}
-
-	// Check for go.mod (Go)
-	if _, err := os.Stat(filepath.Join(repoPath, "go.mod")); err == nil {
-		return "Go", "Unknown"
-	}
-
-	// Check for Cargo.toml (Rust)
-	if _, err := os.Stat(filepath.Join(repoPath, "Cargo.toml")); err == nil {
-		return "Rust", "Unknown"
-	}
-
-	return "Unknown", "Unknown"
-}
-
-func checkProgress(repoPath string) (int, string) {
-	// Check for .stackshift-state.json
-	stateFile := filepath.Join(repoPath, ".stackshift-state.json")
-	if _, err := os.Stat(stateFile); err == nil {
-		// Parse state file (simplified)
-		// Would actually parse JSON and check completedSteps
-		return 0, "not_started"
-	}
-
-	return 0, "not_started"
-}
-
 func (m model) Init() tea.Cmd {
+	go startRepoWatcher(m.searchPath, m.repos)
 	return tickCmd()
 }
 
@@ -227,11 +233,19 @@ type tickMsg time.Time
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.mode == SelectMode && m.filtering {
+			return m.handleFilterKey(msg)
+		}
+		if m.mode == SettingsMode && m.editingTargetStack {
+			return m.handleTargetStackKey(msg)
+		}
 		return m.handleKeyPress(msg.String())
 
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		m.viewport.Width = msg.Width
+		m.viewport.Height = msg.Height - 10
 
 	case tickMsg:
 		// Update spinner frame
@@ -268,6 +282,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case executionCompleteMsg:
 		m.results = msg.results
+		m.failureSummary = msg.summary
 		m.executionDone = true
 		m.mode = ResultsMode
 
@@ -277,6 +292,28 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if len(m.logs) > 50 {
 			m.logs = m.logs[len(m.logs)-50:]
 		}
+
+	case repoChangedMsg:
+		found := false
+		for i, r := range m.repos {
+			if r.Path == msg.repo.Path {
+				if msg.repo.Language != "" {
+					m.repos[i].Language = msg.repo.Language
+					m.repos[i].Framework = msg.repo.Framework
+					m.repos[i].PackageManager = msg.repo.PackageManager
+					m.repos[i].Monorepo = msg.repo.Monorepo
+					m.repos[i].Workspaces = msg.repo.Workspaces
+				}
+				m.repos[i].CurrentGear = msg.repo.CurrentGear
+				m.repos[i].Status = msg.repo.Status
+				found = true
+				break
+			}
+		}
+		if !found {
+			m.repos = append(m.repos, msg.repo)
+		}
+		m.filtered = filterRepos(m.repos, m.filterInput.Value())
 	}
 
 	return m, nil
@@ -304,23 +341,42 @@ func (m model) handleSelectMode(key string) (tea.Model, tea.Cmd) {
 	case "q", "ctrl+c":
 		return m, tea.Quit
 
+	case "/": // Open the fuzzy filter prompt
+		m.filtering = true
+		m.filterInput.Focus()
+		return m, textinput.Blink
+
 	case "up", "k":
 		if m.cursor > 0 {
 			m.cursor--
 		}
 
 	case "down", "j":
-		if m.cursor < len(m.repos)-1 {
+		if m.cursor < len(m.filtered)-1 {
 			m.cursor++
 		}
 
+	case "ctrl+d": // Page down
+		m.cursor += m.pageSize()
+		if m.cursor > len(m.filtered)-1 {
+			m.cursor = len(m.filtered) - 1
+		}
+
+	case "ctrl+u": // Page up
+		m.cursor -= m.pageSize()
+		if m.cursor < 0 {
+			m.cursor = 0
+		}
+
 	case " ": // Space to toggle selection
-		repo := m.repos[m.cursor]
-		m.selectedRepos[repo.Name] = !m.selectedRepos[repo.Name]
+		if m.cursor < len(m.filtered) {
+			repo := m.filtered[m.cursor].repo
+			m.selectedRepos[repo.Name] = !m.selectedRepos[repo.Name]
+		}
 
-	case "a": // Select all
-		for _, repo := range m.repos {
-			m.selectedRepos[repo.Name] = true
+	case "a": // Select all (in the current filtered view)
+		for _, fr := range m.filtered {
+			m.selectedRepos[fr.repo.Name] = true
 		}
 
 	case "n": // Select none
@@ -347,6 +403,93 @@ func (m model) handleSelectMode(key string) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// pageSize is how many rows ctrl+d/ctrl+u jump by, derived from the
+// viewport's height so a page jump always covers exactly one screenful.
+func (m model) pageSize() int {
+	if m.viewport.Height > 0 {
+		return m.viewport.Height
+	}
+	return 10
+}
+
+// cursorYOffset computes the viewport scroll offset needed to keep the
+// cursor row on screen, since the viewport itself doesn't know which line
+// a freshly-rebuilt content string's cursor lives on.
+func (m model) cursorYOffset() int {
+	height := m.viewport.Height
+	if height <= 0 {
+		return 0
+	}
+
+	offset := m.viewport.YOffset
+	if m.cursor < offset {
+		offset = m.cursor
+	} else if m.cursor >= offset+height {
+		offset = m.cursor - height + 1
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return offset
+}
+
+// handleFilterKey routes key presses while the fuzzy filter prompt is open.
+// Everything but esc/enter is forwarded to the textinput so typing, arrow
+// keys, and backspace behave the way users expect from a text field.
+func (m model) handleFilterKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.filtering = false
+		m.filterInput.SetValue("")
+		m.filterInput.Blur()
+		m.filtered = filterRepos(m.repos, "")
+		m.cursor = 0
+		return m, nil
+
+	case "enter":
+		m.filtering = false
+		m.filterInput.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.filterInput, cmd = m.filterInput.Update(msg)
+	m.filtered = filterRepos(m.repos, m.filterInput.Value())
+	if m.cursor > len(m.filtered)-1 {
+		m.cursor = len(m.filtered) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	return m, cmd
+}
+
+// handleTargetStackKey routes key presses while the greenfield TargetStack
+// text input is focused, mirroring handleFilterKey: esc/enter commit and
+// exit, everything else is forwarded to the textinput.
+func (m model) handleTargetStackKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "enter":
+		m.editingTargetStack = false
+		m.targetStack = m.targetStackInput.Value()
+		m.targetStackInput.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.targetStackInput, cmd = m.targetStackInput.Update(msg)
+	return m, cmd
+}
+
+// maxSettingsCursor is the highest valid settingsCursor: the TargetStack
+// row only exists (and so is only reachable) for the greenfield route.
+func (m model) maxSettingsCursor() int {
+	if m.route == "greenfield" {
+		return 6
+	}
+	return 5
+}
+
 func (m model) handleSettingsMode(key string) (tea.Model, tea.Cmd) {
 	switch key {
 	case "q", "esc":
@@ -358,10 +501,26 @@ func (m model) handleSettingsMode(key string) (tea.Model, tea.Cmd) {
 		}
 
 	case "down", "j":
-		if m.settingsCursor < 5 { // 6 settings total
+		if m.settingsCursor < m.maxSettingsCursor() {
 			m.settingsCursor++
 		}
 
+	case "+", "=", "l":
+		if m.settingsCursor == 5 {
+			if max := runtime.NumCPU() * 2; m.parallelLimit < max {
+				m.parallelLimit++
+				m.persistSettingsConfig()
+			}
+		}
+
+	case "-", "h":
+		if m.settingsCursor == 5 {
+			if m.parallelLimit > 1 {
+				m.parallelLimit--
+				m.persistSettingsConfig()
+			}
+		}
+
 	case "enter", " ":
 		// Toggle or change setting
 		switch m.settingsCursor {
@@ -371,6 +530,9 @@ func (m model) handleSettingsMode(key string) (tea.Model, tea.Cmd) {
 			} else {
 				m.route = "greenfield"
 			}
+			if m.settingsCursor > m.maxSettingsCursor() {
+				m.settingsCursor = m.maxSettingsCursor()
+			}
 		case 1: // Transmission
 			if m.transmission == "manual" {
 				m.transmission = "cruise-control"
@@ -396,8 +558,17 @@ func (m model) handleSettingsMode(key string) (tea.Model, tea.Cmd) {
 		case 4: // AI Backend
 			m.useClaudeCode = !m.useClaudeCode
 		case 5: // Parallel limit
-			// Could add +/- controls here
+			// +/- (and h/l) above handle adjustment; enter/space is a no-op here.
+		case 6: // Target stack (greenfield only)
+			if m.route == "greenfield" {
+				m.editingTargetStack = true
+				m.targetStackInput.SetValue(m.targetStack)
+				m.targetStackInput.Focus()
+				return m, textinput.Blink
+			}
 		}
+
+		m.persistSettingsConfig()
 	}
 
 	return m, nil
@@ -431,6 +602,7 @@ func (m model) handleResultsMode(key string) (tea.Model, tea.Cmd) {
 	case "q", "enter", "esc":
 		m.mode = SelectMode
 		m.selectedRepos = make(map[string]bool)
+		m.failureSummary = ""
 	}
 
 	return m, nil
@@ -453,13 +625,56 @@ func (m model) startExecution() tea.Cmd {
 			Clarification:  m.clarification,
 			Implementation: m.implementation,
 			TargetStack:    m.targetStack,
+			Budgets:        activeProfile.Budgets,
+		}
+
+		var orchestrator *Orchestrator
+		if flagResume != "" {
+			var err error
+			orchestrator, err = NewOrchestratorResume(flagResume, selectedRepos, settings, m.parallelLimit, m.useClaudeCode)
+			if err != nil {
+				return logMsg{message: fmt.Sprintf("Resume failed: %v", err)}
+			}
+		} else {
+			orchestrator = NewOrchestrator(selectedRepos, settings, m.parallelLimit, m.useClaudeCode)
 		}
 
-		orchestrator := NewOrchestrator(selectedRepos, settings, m.parallelLimit, m.useClaudeCode)
+		if flagTaskGraph != "" {
+			orchestrator.SetTaskGraph(flagTaskGraph)
+		}
+		if flagExclude != "" {
+			orchestrator.SetExcludedGears(strings.Split(flagExclude, ","))
+		}
+		if flagBackend != "" {
+			if err := orchestrator.SetBackend(flagBackend); err != nil {
+				return logMsg{message: fmt.Sprintf("Backend error: %v", err)}
+			}
+		}
+		orchestrator.SetSearchPath(m.searchPath)
+		if flagSpaceURL != "" {
+			orchestrator.SetSpaceURL(flagSpaceURL, flagSpaceToken)
+		}
+		orchestrator.SetNoReport(flagNoReport)
+		if flagCacheDir != "" {
+			orchestrator.SetCacheDir(flagCacheDir)
+		}
+		orchestrator.SetNoCache(flagNoCache)
+		orchestrator.SetForce(flagForce)
+		orchestrator.SetRestart(flagRestart)
+		if flagOnly != "" {
+			orchestrator.SetOnlyGears(strings.Split(flagOnly, ","))
+		}
+		flags := rootCmd.Flags()
+		orchestrator.SetFlagOverrides(flags.Changed("transmission"), flags.Changed("implementation"))
+		orchestrator.SetProfile(activeProfile)
+		orchestrator.AddProgressSink(TUIProgressSink{})
 
 		// Run in background and collect results
 		results, err := orchestrator.Run()
 		if err != nil {
+			if batchErr, ok := err.(*BatchError); ok {
+				return executionCompleteMsg{results: results, summary: batchErr.GroupedSummary()}
+			}
 			return logMsg{message: fmt.Sprintf("Execution error: %v", err)}
 		}
 
@@ -486,17 +701,19 @@ func (m model) View() string {
 
 func (m model) viewSelectMode() string {
 	s := titleStyle.Render("ğŸš— STACKSHIFT") + "\n\n"
-	s += fmt.Sprintf("Discovered %d repositories\n\n", len(m.repos))
+	if m.filterInput.Value() != "" {
+		s += fmt.Sprintf("Discovered %d repositories (%d match \"%s\")\n\n", len(m.repos), len(m.filtered), m.filterInput.Value())
+	} else {
+		s += fmt.Sprintf("Discovered %d repositories\n\n", len(m.repos))
+	}
 
-	// Show repos
-	start := m.scrollOffset
-	end := start + (m.height - 10)
-	if end > len(m.repos) {
-		end = len(m.repos)
+	if m.filtering {
+		s += m.filterInput.View() + "\n\n"
 	}
 
-	for i := start; i < end; i++ {
-		repo := m.repos[i]
+	var lines strings.Builder
+	for i, fr := range m.filtered {
+		repo := fr.repo
 
 		cursor := "  "
 		if i == m.cursor {
@@ -513,24 +730,39 @@ func (m model) viewSelectMode() string {
 		case "complete":
 			status = "âœ…"
 		case "in_progress":
-			status = fmt.Sprintf("ğŸš— Gear %d", repo.CurrentGear)
+			status = selectedStyle.Render(fmt.Sprintf("[resumable] ğŸš— Gear %d", repo.CurrentGear))
 		case "failed":
 			status = "âŒ"
 		default:
 			status = "â¸ï¸"
 		}
 
+		name := highlightMatches(repo.Name, fr.nameMatches(len(repo.Name)))
+
+		lang := repo.Language
+		if repo.Framework != "" {
+			lang = fmt.Sprintf("%s (%s)", lang, repo.Framework)
+		}
+		if repo.Monorepo {
+			lang += fmt.Sprintf(" [monorepo, %d workspaces]", len(repo.Workspaces))
+		}
+
 		line := fmt.Sprintf("%s%s %s | %s | %s",
 			cursor,
 			checkbox,
-			repo.Name,
-			repo.Language,
+			name,
+			lang,
 			status,
 		)
 
-		s += line + "\n"
+		lines.WriteString(line + "\n")
 	}
 
+	vp := m.viewport
+	vp.SetContent(lines.String())
+	vp.YOffset = m.cursorYOffset()
+	s += vp.View()
+
 	// Show selection count
 	selectedCount := 0
 	for _, selected := range m.selectedRepos {
@@ -546,7 +778,7 @@ func (m model) viewSelectMode() string {
 		m.transmission,
 	))
 	s += "\n"
-	s += helpStyle.Render("â†‘/â†“: Navigate | Space: Toggle | a: All | n: None | s: Settings | Enter: Start | q: Quit")
+	s += helpStyle.Render("â†‘/â†“: Navigate | /: Filter | ctrl+d/u: Page | Space: Toggle | a: All | n: None | s: Settings | Enter: Start | q: Quit")
 
 	return s
 }
@@ -569,7 +801,19 @@ func (m model) viewSettingsMode() string {
 			}
 			return "OpenCode (CoPilot)"
 		}(), ""},
-		{"Parallel Limit", fmt.Sprintf("%d repos", m.parallelLimit), "+/- to adjust"},
+		{"Parallel Limit", fmt.Sprintf("%d repos", m.parallelLimit), fmt.Sprintf("+/- or h/l to adjust, range [1, %d]", runtime.NumCPU()*2)},
+	}
+
+	if m.route == "greenfield" {
+		value := m.targetStack
+		if value == "" {
+			value = "(unset)"
+		}
+		settings = append(settings, struct {
+			name    string
+			value   string
+			options string
+		}{"Target Stack", value, "enter to edit"})
 	}
 
 	for i, setting := range settings {
@@ -578,6 +822,11 @@ func (m model) viewSettingsMode() string {
 			cursor = cursorStyle.Render("â†’ ")
 		}
 
+		if i == 6 && m.editingTargetStack {
+			s += fmt.Sprintf("%s%s: %s\n", cursor, setting.name, m.targetStackInput.View())
+			continue
+		}
+
 		s += fmt.Sprintf("%s%s: %s\n", cursor, setting.name, selectedStyle.Render(setting.value))
 		if setting.options != "" {
 			s += fmt.Sprintf("   (%s)\n", helpStyle.Render(setting.options))
@@ -630,10 +879,10 @@ func (m model) viewExecutingMode() string {
 	} else {
 		// Show progress for each running task
 		gearIcons := map[int]string{
-			1: "ğŸ”", // Analyze
-			2: "ğŸ“", // Reverse Engineer
-			3: "ğŸ“", // Create Specs
-			4: "ğŸ”", // Gap Analysis
+			1: "ğŸ”",  // Analyze
+			2: "ğŸ“",  // Reverse Engineer
+			3: "ğŸ“",  // Create Specs
+			4: "ğŸ”",  // Gap Analysis
 			5: "âœï¸", // Complete Spec
 			6: "ğŸ”¨", // Implement
 		}
@@ -707,15 +956,17 @@ func (m model) viewResultsMode() string {
 		}
 	}
 
+	if m.failureSummary != "" {
+		s += "\n" + titleStyle.Render("Summary") + "\n" + m.failureSummary + "\n"
+	}
+
 	s += "\n"
 	s += helpStyle.Render("Enter/q: Return to main menu")
 
 	return s
 }
 
-func main() {
-	// ASCII art splash
-	fmt.Println(`
+const splashBanner = `
 â•”â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•—
 â•‘                                                           â•‘
 â•‘   â–ˆâ–ˆâ–ˆâ–ˆâ–ˆâ–ˆâ–ˆâ•—â–ˆâ–ˆâ–ˆâ–ˆâ–ˆâ–ˆâ–ˆâ–ˆâ•— â–ˆâ–ˆâ–ˆâ–ˆâ–ˆâ•—  â–ˆâ–ˆâ–ˆâ–ˆâ–ˆâ–ˆâ•—â–ˆâ–ˆâ•—  â–ˆâ–ˆâ•—              â•‘
@@ -735,11 +986,135 @@ func main() {
 â•‘   Reverse Engineering â†’ Specification-Driven Development â•‘
 â•‘                                                           â•‘
 â•šâ•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•â•
-`)
+`
 
-	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+// Flags bound by rootCmd's flag set in init() below. Kept as package-level
+// vars rather than threaded through every function, since both the TUI
+// (initialModel/startExecution) and headless mode read them.
+var (
+	flagPath           string
+	flagRoute          string
+	flagTransmission   string
+	flagClarification  string
+	flagImplementation string
+	flagParallel       int
+	flagBackend        string
+	flagSelect         string
+	flagExclude        string
+	flagTaskGraph      string
+	flagResume         string
+	flagHeadless       bool
+	flagJSON           bool
+	flagSpaceURL       string
+	flagSpaceToken     string
+	flagNoReport       bool
+	flagForce          bool
+	flagNoCache        bool
+	flagCacheDir       string
+	flagRestart        bool
+	flagOnly           string
+	flagProfile        string
+
+	activeProfile Profile
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "stackshift [path]",
+	Short: "Batch-migrate repositories through reverse-engineering and spec-driven gears",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 {
+			flagPath = args[0]
+		}
+		applyConfigDefaults(cmd.Flags())
+
+		profile, err := resolveProfile(flagProfile)
+		if err != nil {
+			return err
+		}
+		applyProfileDefaults(cmd.Flags(), profile)
+		activeProfile = profile
+
+		if flagHeadless {
+			return runHeadless(cmd.Flags())
+		}
+
+		fmt.Println(splashBanner)
+
+		program = tea.NewProgram(initialModel(), tea.WithAltScreen())
+		_, err = program.Run()
+		return err
+	},
+}
+
+func init() {
+	flags := rootCmd.Flags()
+	flags.StringVar(&flagPath, "path", "", "root directory to scan for repos (default ~/git)")
+	flags.StringVar(&flagRoute, "route", "brownfield", "greenfield | brownfield")
+	flags.StringVar(&flagTransmission, "transmission", "cruise-control", "manual | cruise-control")
+	flags.StringVar(&flagClarification, "clarification", "defer", "prompt | defer | skip")
+	flags.StringVar(&flagImplementation, "implementation", "p0", "none | p0 | p0_p1 | all")
+	flags.IntVar(&flagParallel, "parallel", 3, "max repos to process at once")
+	flags.StringVar(&flagBackend, "backend", "", "AI CLI backend to dispatch gears to (claude-code, opencode, cursor, aider, codex, gemini); when unset, --headless uses ~/.stackshift/backends.yaml's default, then claude-code, and the TUI uses its AI Backend setting (claude-code or opencode)")
+	flags.StringVar(&flagSelect, "select", "", "comma-separated glob patterns matching repo names to include")
+	flags.StringVar(&flagExclude, "exclude", "", "comma-separated gear names to skip")
+	flags.StringVar(&flagTaskGraph, "task-graph", "", "path to a YAML task graph override")
+	flags.StringVar(&flagResume, "resume", "", "resume a previous run by its run ID")
+	flags.BoolVar(&flagHeadless, "headless", false, "drive gears directly without the interactive TUI")
+	flags.BoolVar(&flagJSON, "json", false, "in --headless mode, emit NDJSON events instead of plain text")
+	flags.StringVar(&flagSpaceURL, "space-url", "", "remote Spaces endpoint to report run/task progress to")
+	flags.StringVar(&flagSpaceToken, "space-token", "", "bearer token for --space-url")
+	flags.BoolVar(&flagNoReport, "no-report", false, "disable Spaces reporting even if --space-url is set")
+	flags.BoolVar(&flagForce, "force", false, "skip the gear cache and run everything fresh")
+	flags.BoolVar(&flagNoCache, "no-cache", false, "disable gear caching entirely")
+	flags.StringVar(&flagCacheDir, "cache-dir", "", "directory to cache completed gears in (default ~/.stackshift-cache)")
+	flags.BoolVar(&flagRestart, "restart", false, "ignore prior completion state and start every selected repo over from gear 1")
+	flags.StringVar(&flagOnly, "only", "", "comma-separated gear names to run, skipping the rest of the graph")
+	flags.StringVar(&flagProfile, "profile", "", "named preset from ~/.stackshift/profiles.yaml to apply before config.toml (default: profiles.yaml's own default, if set)")
+}
+
+func main() {
+	if err := rootCmd.Execute(); err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
 }
+
+// program is set once by rootCmd's RunE so TUIProgressSink can push live gear events
+// into the Bubble Tea event loop from the orchestrator's goroutines.
+var program *tea.Program
+
+// TUIProgressSink feeds gear events into the running Bubble Tea program as
+// taskUpdateMsg/logMsg, giving the Executing view live progress instead of
+// a single blocking call that only resolves once the whole run is done.
+type TUIProgressSink struct{}
+
+func (TUIProgressSink) OnGearStart(repo string, gear int) {
+	if program != nil {
+		program.Send(taskUpdateMsg{repo: repo, gear: gear, status: "in_progress"})
+	}
+}
+
+func (TUIProgressSink) OnGearLog(repo string, gear int, chunk []byte) {
+	if program == nil {
+		return
+	}
+	line := strings.TrimSpace(string(chunk))
+	if line == "" {
+		return
+	}
+	program.Send(logMsg{message: fmt.Sprintf("[%s gear %d] %s", repo, gear, line)})
+}
+
+func (TUIProgressSink) OnGearComplete(repo string, gear int, result GearResult) {
+	if program == nil {
+		return
+	}
+	status := "complete"
+	if !result.Success {
+		status = "failed"
+	}
+	program.Send(taskUpdateMsg{repo: repo, gear: gear, status: status})
+}
+
+func (TUIProgressSink) OnRunComplete(results []GearResult) {}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ManifestEntry records one completed (repo, gear) unit of work so a
+// crashed or interrupted run can be resumed without redoing finished gears.
+type ManifestEntry struct {
+	Repo        string        `json:"repo"`
+	Gear        string        `json:"gear"`
+	Success     bool          `json:"success"`
+	Duration    time.Duration `json:"duration"`
+	LogPath     string        `json:"logPath"`
+	PromptHash  string        `json:"promptHash"`
+	CompletedAt time.Time     `json:"completedAt"`
+}
+
+// RunManifest is the persisted record of a single orchestrator run, written
+// atomically to <resultsDir>/manifest.json after every gear completion.
+type RunManifest struct {
+	RunID   string          `json:"runId"`
+	Entries []ManifestEntry `json:"entries"`
+
+	path string
+	mu   sync.Mutex
+}
+
+// newRunManifest creates an empty manifest for a freshly started run.
+func newRunManifest(runID, resultsDir string) *RunManifest {
+	return &RunManifest{
+		RunID: runID,
+		path:  filepath.Join(resultsDir, "manifest.json"),
+	}
+}
+
+// loadRunManifest rehydrates a manifest from a prior run's results
+// directory, for use by NewOrchestratorResume.
+func loadRunManifest(runID, resultsDir string) (*RunManifest, error) {
+	path := filepath.Join(resultsDir, "manifest.json")
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return newRunManifest(runID, resultsDir), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m := &RunManifest{path: path}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	m.path = path
+
+	return m, nil
+}
+
+// record appends an entry and flushes the manifest to disk atomically (via
+// write-to-temp-then-rename) so a crash mid-write never leaves a truncated
+// manifest.json behind.
+func (m *RunManifest) record(entry ManifestEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Entries = append(m.Entries, entry)
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, m.path)
+}
+
+// completedPairs returns the set of (repo, gear) pairs that finished
+// successfully, keyed by repo name then gear name.
+func (m *RunManifest) completedPairs() map[string]map[string]bool {
+	completed := make(map[string]map[string]bool)
+	for _, e := range m.Entries {
+		if !e.Success {
+			continue
+		}
+		if completed[e.Repo] == nil {
+			completed[e.Repo] = map[string]bool{}
+		}
+		completed[e.Repo][e.Gear] = true
+	}
+	return completed
+}
+
+// hashPrompt returns a short content hash of a gear's rendered prompt, used
+// both in the manifest and as a cache key for resuming/caching gears.
+func hashPrompt(promptBody []byte) string {
+	sum := sha256.Sum256(promptBody)
+	return hex.EncodeToString(sum[:])[:16]
+}
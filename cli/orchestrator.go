@@ -1,23 +1,73 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 )
 
 type Orchestrator struct {
-	repos          []Repository
-	settings       Settings
-	parallelLimit  int
-	useClaudeCode  bool
-	resultsDir     string
-	runningMutex   sync.Mutex
-	runningTasks   map[string]*exec.Cmd
+	repos         []Repository
+	settings      Settings
+	parallelLimit int
+	useClaudeCode bool
+	resultsDir    string
+	searchPath    string
+	runningMutex  sync.Mutex
+	runningTasks  map[string]*exec.Cmd
+
+	taskGraphPath string
+	excludeGears  map[string]bool
+	backend       Backend
+
+	cacheDir string
+	noCache  bool
+	force    bool
+
+	restart             bool
+	onlyGears           map[string]bool
+	transmissionFixed   bool
+	implementationFixed bool
+	profile             Profile
+
+	runID           string
+	manifest        *RunManifest
+	resumeCompleted map[string]map[string]bool
+
+	summary  *RunSummary
+	spaces   *SpacesConfig
+	noReport bool
+
+	stop            chan struct{}
+	stopOnce        sync.Once
+	shutdownPending chan struct{}
+
+	// rootCtx is cancelled by KillAll (including when wired to SIGINT), so
+	// every in-flight gear's exec.CommandContext sees the cancellation
+	// immediately instead of only the next time it happens to check o.stop.
+	rootCtx    context.Context
+	cancelRoot context.CancelFunc
+
+	progressSink *multiSink
+	progress     *progressTracker
+}
+
+// gearNames maps a gear's numeric position to its canonical name. Gear
+// numbers only exist for logging and the on-disk state file; the task
+// graph in taskgraph.go is the source of truth for ordering.
+var gearNames = map[int]string{
+	1: "analyze",
+	2: "reverse-engineer",
+	3: "create-specs",
+	4: "gap-analysis",
+	5: "complete-spec",
+	6: "implement",
 }
 
 type Settings struct {
@@ -26,113 +76,511 @@ type Settings struct {
 	Clarification  string
 	Implementation string
 	TargetStack    string
-}
-
-type StateFile struct {
-	Version        string   `json:"version"`
-	Created        string   `json:"created"`
-	Updated        string   `json:"updated"`
-	Path           string   `json:"path"`
-	CurrentStep    string   `json:"currentStep"`
-	CompletedSteps []string `json:"completedSteps"`
-	CruiseControl  struct {
-		Enabled              bool   `json:"enabled"`
-		ClarificationStrategy string `json:"clarificationStrategy"`
-		ImplementationScope  string `json:"implementationScope"`
-		AutoMode             bool   `json:"autoMode"`
-	} `json:"cruiseControl"`
+	Budgets        map[string]Budget
 }
 
 func NewOrchestrator(repos []Repository, settings Settings, parallelLimit int, useClaudeCode bool) *Orchestrator {
 	homeDir, _ := os.UserHomeDir()
-	resultsDir := filepath.Join(homeDir, ".stackshift-results", time.Now().Format("2006-01-02_15-04-05"))
+	runID := time.Now().Format("2006-01-02_15-04-05")
+	resultsDir := filepath.Join(homeDir, ".stackshift-results", runID)
 	os.MkdirAll(resultsDir, 0755)
 
+	ctx, cancel := context.WithCancel(context.Background())
+
 	return &Orchestrator{
-		repos:         repos,
-		settings:      settings,
-		parallelLimit: parallelLimit,
-		useClaudeCode: useClaudeCode,
-		resultsDir:    resultsDir,
-		runningTasks:  make(map[string]*exec.Cmd),
+		repos:           repos,
+		settings:        settings,
+		parallelLimit:   parallelLimit,
+		useClaudeCode:   useClaudeCode,
+		resultsDir:      resultsDir,
+		cacheDir:        filepath.Join(homeDir, ".stackshift-cache"),
+		runningTasks:    make(map[string]*exec.Cmd),
+		runID:           runID,
+		manifest:        newRunManifest(runID, resultsDir),
+		stop:            make(chan struct{}),
+		shutdownPending: make(chan struct{}),
+		rootCtx:         ctx,
+		cancelRoot:      cancel,
+		progressSink:    &multiSink{sinks: []ProgressSink{NewFileProgressSink(resultsDir)}},
+		progress:        newProgressTracker(),
 	}
 }
 
-func (o *Orchestrator) Run() ([]GearResult, error) {
-	results := []GearResult{}
+// NewOrchestratorResume rehydrates an orchestrator from a prior run's
+// manifest.json under ~/.stackshift-results/<runID>/, so a crashed or
+// Ctrl-C'd run can pick up where it left off instead of redoing gears that
+// already succeeded. Repos/settings are supplied fresh (e.g. from
+// discoverRepositories) since the manifest only records completed work, not
+// the original invocation's full configuration.
+func NewOrchestratorResume(runID string, repos []Repository, settings Settings, parallelLimit int, useClaudeCode bool) (*Orchestrator, error) {
+	homeDir, _ := os.UserHomeDir()
+	resultsDir := filepath.Join(homeDir, ".stackshift-results", runID)
+
+	manifest, err := loadRunManifest(runID, resultsDir)
+	if err != nil {
+		return nil, fmt.Errorf("loading manifest for run %s: %w", runID, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Orchestrator{
+		repos:           repos,
+		settings:        settings,
+		parallelLimit:   parallelLimit,
+		useClaudeCode:   useClaudeCode,
+		resultsDir:      resultsDir,
+		cacheDir:        filepath.Join(homeDir, ".stackshift-cache"),
+		runningTasks:    make(map[string]*exec.Cmd),
+		runID:           runID,
+		manifest:        manifest,
+		resumeCompleted: manifest.completedPairs(),
+		stop:            make(chan struct{}),
+		shutdownPending: make(chan struct{}),
+		rootCtx:         ctx,
+		cancelRoot:      cancel,
+		progressSink:    &multiSink{sinks: []ProgressSink{NewFileProgressSink(resultsDir)}},
+		progress:        newProgressTracker(),
+	}, nil
+}
+
+// AddProgressSink attaches another destination for live gear events (e.g. an
+// HTTPProgressSink pointed at a remote dashboard), on top of the default
+// file-tailing NDJSON sink.
+func (o *Orchestrator) AddProgressSink(sink ProgressSink) {
+	o.progressSink.sinks = append(o.progressSink.sinks, sink)
+}
+
+// SetTaskGraph points the orchestrator at a --task-graph YAML override file
+// (see taskgraph.go). An empty path keeps the built-in gear graph.
+func (o *Orchestrator) SetTaskGraph(path string) {
+	o.taskGraphPath = path
+}
+
+// SetBackend overrides which AI backend runs every gear, taking priority
+// over the legacy useClaudeCode bool. Returns an error if name isn't
+// registered (see backend.go).
+func (o *Orchestrator) SetBackend(name string) error {
+	b, err := resolveBackend(name)
+	if err != nil {
+		return err
+	}
+	o.backend = b
+	return nil
+}
+
+// SetExcludedGears skips the named gears entirely, as if they had no nodes
+// in the task graph.
+func (o *Orchestrator) SetExcludedGears(gears []string) {
+	o.excludeGears = make(map[string]bool, len(gears))
+	for _, g := range gears {
+		o.excludeGears[g] = true
+	}
+}
+
+// SetSearchPath records the root directory repos were discovered under, so
+// the written RunSummary's RepoPath reflects the actual invocation.
+func (o *Orchestrator) SetSearchPath(path string) {
+	o.searchPath = path
+}
+
+// SetSpaceURL points the orchestrator at a remote Spaces endpoint for live
+// run reporting (see spaces.go): a POST when the run starts, a PATCH per
+// finished gear, and a PATCH when it ends. An empty url disables reporting.
+func (o *Orchestrator) SetSpaceURL(url, bearerToken string) {
+	if url == "" {
+		o.spaces = nil
+		return
+	}
+	o.spaces = NewSpacesConfig(url, bearerToken)
+}
+
+// SetNoReport disables Spaces reporting even when a space URL is
+// configured, as an escape hatch for runs that shouldn't leave the machine.
+func (o *Orchestrator) SetNoReport(v bool) {
+	o.noReport = v
+}
+
+// SetCacheDir overrides where completed gears' artifacts are cached,
+// instead of the default ~/.stackshift-cache.
+func (o *Orchestrator) SetCacheDir(dir string) {
+	o.cacheDir = dir
+}
+
+// SetNoCache disables gear caching entirely: every gear runs uncached and
+// nothing is written to the cache directory.
+func (o *Orchestrator) SetNoCache(v bool) {
+	o.noCache = v
+}
+
+// SetForce skips cache lookups (but still populates the cache on success),
+// so a user can force a fresh run without losing the speedup on the next one.
+func (o *Orchestrator) SetForce(v bool) {
+	o.force = v
+}
+
+// SetRestart ignores all prior completion state (the run manifest, every
+// repo's .stackshift-state.json, and its SettingsSnapshot) so each selected
+// repo starts over from gear 1 instead of resuming where it left off.
+func (o *Orchestrator) SetRestart(v bool) {
+	o.restart = v
+}
+
+// SetOnlyGears restricts a run to exactly these gear names, for surgically
+// re-running one step without walking the rest of the graph. An empty list
+// runs everything the task graph and --exclude allow.
+func (o *Orchestrator) SetOnlyGears(gears []string) {
+	o.onlyGears = make(map[string]bool, len(gears))
+	for _, g := range gears {
+		o.onlyGears[g] = true
+	}
+}
+
+// SetFlagOverrides records whether the caller explicitly passed
+// --transmission/--implementation, so effectiveSettings knows it's safe to
+// fall back to a repo's own last SettingsSnapshot when they didn't.
+func (o *Orchestrator) SetFlagOverrides(transmissionFixed, implementationFixed bool) {
+	o.transmissionFixed = transmissionFixed
+	o.implementationFixed = implementationFixed
+}
+
+// SetProfile records which named profile (if any) produced this run's
+// settings, so it shows up in the run summary for support dumps and
+// troubleshooting. Passing a zero-valued Profile (Name == "") is a no-op.
+func (o *Orchestrator) SetProfile(p Profile) {
+	o.profile = p
+}
+
+// effectiveSettings returns the Settings to use for repo, substituting
+// Transmission/Implementation from the repo's own last
+// .stackshift-state.json SettingsSnapshot when the caller didn't explicitly
+// override them. This lets a repo someone previously ran in manual mode (or
+// with a wider implementation scope) keep that choice across invocations
+// instead of reverting to whatever the next command line happens to pass.
+func (o *Orchestrator) effectiveSettings(repo Repository) Settings {
+	settings := o.settings
+	if o.restart {
+		return settings
+	}
 
-	// Determine which gears to run based on transmission mode
-	gears := []int{}
-	if o.settings.Transmission == "cruise-control" {
-		gears = []int{1, 2, 3, 4, 5, 6} // All gears
-	} else {
-		// Manual mode - run just the next incomplete gear for each repo
-		gears = []int{1} // Start with Gear 1
+	state, err := loadStateFile(repo.Path)
+	if err != nil {
+		return settings
 	}
 
-	// Process repos in batches based on parallel limit
-	for i := 0; i < len(o.repos); i += o.parallelLimit {
-		end := i + o.parallelLimit
-		if end > len(o.repos) {
-			end = len(o.repos)
+	if !o.transmissionFixed && state.SettingsSnapshot.Transmission != "" {
+		settings.Transmission = state.SettingsSnapshot.Transmission
+	}
+	if !o.implementationFixed && state.SettingsSnapshot.Implementation != "" {
+		settings.Implementation = state.SettingsSnapshot.Implementation
+	}
+	return settings
+}
+
+func (o *Orchestrator) Run() ([]GearResult, error) {
+	graph, err := loadTaskGraph(o.taskGraphPath)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := graph.withExclusions(o.excludeGears)
+
+	if len(o.onlyGears) > 0 {
+		only := make(map[string]*TaskNode, len(o.onlyGears))
+		for name, node := range nodes {
+			if o.onlyGears[name] {
+				only[name] = node
+			}
 		}
+		nodes = only
+	}
 
-		batch := o.repos[i:end]
+	o.summary = newRunSummary(o.runID, strings.Join(os.Args, " "), o.searchPath)
+	if o.profile.Name != "" {
+		o.summary.Profile = &o.profile
+	}
+	if o.spaces != nil && !o.noReport {
+		o.spaces.reportRunStart(o.summary)
+	}
+
+	results := o.runGraph(nodes)
+	o.progressSink.OnRunComplete(results)
 
-		// Run this batch
-		batchResults := o.runBatch(batch, gears)
-		results = append(results, batchResults...)
+	o.summary.finish(o.resultsDir, results)
+	if o.spaces != nil && !o.noReport {
+		o.spaces.reportRunFinish(o.summary)
 	}
 
-	return results, nil
+	batchErr := &BatchError{}
+	for _, r := range results {
+		if r.Success {
+			continue
+		}
+		batchErr.Failures = append(batchErr.Failures, &GearFailure{
+			Repo:       r.Repo,
+			Gear:       r.Gear,
+			ExitCode:   r.ExitCode,
+			StderrTail: r.StderrTail,
+			Reason:     r.Message,
+		})
+	}
+	if len(batchErr.Failures) == 0 {
+		return results, nil
+	}
+	return results, batchErr
 }
 
-func (o *Orchestrator) runBatch(batch []Repository, gears []int) []GearResult {
+// runGraph topologically walks the task graph, dispatching any (repo, gear)
+// pair whose prerequisites are satisfied up to parallelLimit concurrent
+// gears. Unlike the old strictly-batched-by-repo loop, this fills idle
+// workers with ready work from any repo, so a slow gear in one repo doesn't
+// stall gears that are ready in another.
+func (o *Orchestrator) runGraph(nodes map[string]*TaskNode) []GearResult {
+	completed := make(map[string]map[string]bool, len(o.repos))
+	inFlight := make(map[string]map[string]bool, len(o.repos))
+	failed := make(map[string]bool, len(o.repos))
+	manualAdvanced := make(map[string]bool, len(o.repos))
+	repoByName := make(map[string]Repository, len(o.repos))
+	for _, r := range o.repos {
+		repoByName[r.Name] = r
+		completed[r.Name] = map[string]bool{}
+		inFlight[r.Name] = map[string]bool{}
+
+		// Merge in gears already completed according to the run manifest
+		// (resumed runs) and the repo's own .stackshift-state.json, so
+		// Ctrl-C or a crashed child doesn't waste tokens redoing them.
+		// --restart skips this so every selected repo starts over, and gears
+		// named by --only are left unmarked so they re-run.
+		if !o.restart {
+			for gear := range o.resumeCompleted[r.Name] {
+				if !o.onlyGears[gear] {
+					completed[r.Name][gear] = true
+				}
+			}
+			for _, step := range readCompletedSteps(r.Path) {
+				if !o.onlyGears[step] {
+					completed[r.Name][step] = true
+				}
+			}
+		}
+	}
+
+	results := []GearResult{}
+	remaining := 0
+	for _, r := range o.repos {
+		for name := range nodes {
+			if !completed[r.Name][name] {
+				remaining++
+			}
+		}
+	}
+
+	resultCh := make(chan GearResult)
+	sem := make(chan struct{}, o.parallelLimit)
+	var mu sync.Mutex
 	var wg sync.WaitGroup
-	resultsChan := make(chan GearResult, len(batch)*len(gears))
 
-	for _, repo := range batch {
-		wg.Add(1)
-		go func(r Repository) {
-			defer wg.Done()
+	// dispatch launches every ready (repo, gear) pair it can find, up to
+	// however many semaphore slots are currently free.
+	dispatch := func() {
+		select {
+		case <-o.stop:
+			// A shutdown was requested: let in-flight gears finish but
+			// don't start any new ones.
+			return
+		default:
+		}
 
-			for _, gear := range gears {
-				result := o.runGear(r, gear)
-				resultsChan <- result
+		mu.Lock()
+		defer mu.Unlock()
+
+		for _, r := range o.repos {
+			// A repo not running cruise-control only ever advances one
+			// gear per Run() call, so different repos can sit at
+			// different manual steps instead of all lockstepping.
+			manual := o.effectiveSettings(r).Transmission != "cruise-control"
+
+			if failed[r.Name] && !manual {
+				continue
+			}
+			if manual && manualAdvanced[r.Name] {
+				continue
+			}
+
+			for name, node := range nodes {
+				if completed[r.Name][name] || inFlight[r.Name][name] {
+					continue
+				}
 
-				// If gear failed and we're in cruise control, stop this repo
-				if !result.Success && o.settings.Transmission == "cruise-control" {
-					break
+				ready := true
+				for _, dep := range node.DependsOn {
+					if !completed[r.Name][dep] {
+						ready = false
+						break
+					}
+				}
+				if !ready {
+					continue
+				}
+
+				select {
+				case sem <- struct{}{}:
+					inFlight[r.Name][name] = true
+					if manual {
+						manualAdvanced[r.Name] = true
+					}
+					wg.Add(1)
+					go func(repo Repository, gearName string) {
+						defer wg.Done()
+						defer func() { <-sem }()
+						resultCh <- o.runGear(repo, getGearNumber(gearName))
+					}(r, name)
+				default:
+					return
 				}
 			}
-		}(repo)
+		}
 	}
 
-	wg.Wait()
-	close(resultsChan)
+	// dispatch's first call must happen before the closer goroutine below,
+	// so wg.Add for the first wave of gears runs before wg.Wait can ever
+	// observe the counter at zero. Starting the closer first would race:
+	// if it's scheduled before dispatch's wg.Add calls, Wait returns
+	// immediately, closes resultCh, and a gear finishing moments later
+	// panics sending on a closed channel.
+	dispatch()
+
+	go func() {
+		wg.Wait()
+		close(resultCh)
+	}()
+
+	for remaining > 0 {
+		result, ok := <-resultCh
+		if !ok {
+			break
+		}
+
+		mu.Lock()
+		gearName := gearNames[result.Gear]
+		delete(inFlight[result.Repo], gearName)
+		if result.Success {
+			completed[result.Repo][gearName] = true
+		} else if o.effectiveSettings(repoByName[result.Repo]).Transmission == "cruise-control" {
+			failed[result.Repo] = true
+		}
+		mu.Unlock()
+
+		o.manifest.record(ManifestEntry{
+			Repo:        result.Repo,
+			Gear:        gearName,
+			Success:     result.Success,
+			LogPath:     filepath.Join(o.resultsDir, fmt.Sprintf("%s_gear%d.log", result.Repo, result.Gear)),
+			CompletedAt: time.Now(),
+		})
 
-	results := []GearResult{}
-	for result := range resultsChan {
 		results = append(results, result)
+		remaining--
+		dispatch()
 	}
 
+	close(o.shutdownPending)
 	return results
 }
 
-func (o *Orchestrator) runGear(repo Repository, gear int) GearResult {
-	gearNames := map[int]string{
-		1: "analyze",
-		2: "reverse-engineer",
-		3: "create-specs",
-		4: "gap-analysis",
-		5: "complete-spec",
-		6: "implement",
+// readCompletedSteps reads a repo's .stackshift-state.json and returns its
+// CompletedSteps, or nil if the file doesn't exist or can't be parsed.
+func readCompletedSteps(repoPath string) []string {
+	state, err := loadStateFile(repoPath)
+	if err != nil {
+		return nil
 	}
 
+	return state.CompletedSteps
+}
+
+// runGear drives one (repo, gear) through as many attempts as its Budget's
+// MaxRetries allows, backing off exponentially between failed attempts.
+// Each attempt gets its own log file and its own entry in the RunSummary,
+// so a flaky first attempt doesn't hide behind the eventual success.
+func (o *Orchestrator) runGear(repo Repository, gear int) GearResult {
 	gearName := gearNames[gear]
+	settings := o.effectiveSettings(repo)
+	budget := o.resolveBudget(repo, gearName)
+
+	var result GearResult
+	attempts := budget.MaxRetries + 1
+	for attempt := 1; attempt <= attempts; attempt++ {
+		taskStart := time.Now()
+		logFile := filepath.Join(o.resultsDir, fmt.Sprintf("%s_gear%d.log", repo.Name, gear))
+		if attempt > 1 {
+			logFile = filepath.Join(o.resultsDir, fmt.Sprintf("%s_gear%d_attempt%d.log", repo.Name, gear, attempt))
+		}
+
+		result = o.runGearAttempt(repo, gear, gearName, settings, budget, logFile)
+
+		o.progress.complete(repo.Name, gear, result.Success)
+		o.progressSink.OnGearComplete(repo.Name, gear, result)
+		o.recordTaskSummary(repo, gear, gearName, logFile, taskStart, result)
+
+		if result.Success || attempt == attempts {
+			break
+		}
+
+		select {
+		case <-o.rootCtx.Done():
+			return result
+		case <-time.After(budget.retryBackoff() * time.Duration(uint(1)<<uint(attempt-1))):
+		}
+	}
+
+	return result
+}
+
+// runGearAttempt runs gearName against repo exactly once: resolving the
+// backend and cache key, executing the child process in its own process
+// group (so a timeout or a cancelled run can take down the whole tree
+// instead of leaking orphans), and validating the result. runGear wraps
+// this in a retry loop per the gear's Budget.
+func (o *Orchestrator) runGearAttempt(repo Repository, gear int, gearName string, settings Settings, budget Budget, logFile string) GearResult {
+	// Resolve the AI backend up front: its name is part of the cache key,
+	// and it's needed either way once the cache is checked.
+	backend := o.backend
+	if backend == nil {
+		name := "opencode"
+		if o.useClaudeCode {
+			name = "claude-code"
+		}
+		var err error
+		backend, err = resolveBackend(name)
+		if err != nil {
+			return GearResult{
+				Repo:    repo.Name,
+				Gear:    gear,
+				Success: false,
+				Message: err.Error(),
+			}
+		}
+	}
+
+	var cacheKey string
+	if !o.noCache {
+		if hash, err := hashGearInputs(repo, gearName, settings, backend.Name()); err == nil {
+			cacheKey = hash
+			if !o.force {
+				if restored, message := o.restoreFromCache(repo, gearName, cacheKey); restored {
+					return GearResult{
+						Repo:    repo.Name,
+						Gear:    gear,
+						Success: true,
+						Message: message,
+						Cached:  true,
+					}
+				}
+			}
+		}
+	}
 
 	// Create log file
-	logFile := filepath.Join(o.resultsDir, fmt.Sprintf("%s_gear%d.log", repo.Name, gear))
 	logF, err := os.Create(logFile)
 	if err != nil {
 		return GearResult{
@@ -144,241 +592,236 @@ func (o *Orchestrator) runGear(repo Repository, gear int) GearResult {
 	}
 	defer logF.Close()
 
-	// Build command based on AI backend
-	var cmd *exec.Cmd
-
-	if o.useClaudeCode {
-		// Use Claude Code
-		cmd = o.buildClaudeCodeCommand(repo, gearName)
-	} else {
-		// Use OpenCode (GitHub CoPilot)
-		cmd = o.buildOpenCodeCommand(repo, gearName)
+	built, err := backend.BuildCommand(repo, gearName, settings)
+	if err != nil {
+		return GearResult{
+			Repo:    repo.Name,
+			Gear:    gear,
+			Success: false,
+			Message: fmt.Sprintf("Failed to build command: %v", err),
+		}
 	}
 
+	// Rebuild around o.rootCtx so a cancelled run (KillAll, or SIGINT in
+	// headless mode) tears this child down immediately rather than only on
+	// its next budget check. The child also gets its own process group, so
+	// killProcessGroup can take down whatever subprocesses it spawns too.
+	cmd := exec.CommandContext(o.rootCtx, built.Path, built.Args[1:]...)
 	cmd.Dir = repo.Path
-	cmd.Stdout = logF
-	cmd.Stderr = logF
+	cmd.Env = built.Env
+	cmd.Stdin = built.Stdin
+	configureProcessGroup(cmd)
+
+	budgetTokenCounter := newTokenCounterWriter(budget.MaxTokens)
+
+	var stderrTail stderrTailWriter
+	out := sinkWriter{sink: o.progressSink, repo: repo.Name, gear: gear}
+	cmd.Stdout = io.MultiWriter(logF, out, budgetTokenCounter)
+	cmd.Stderr = io.MultiWriter(logF, &stderrTail, out)
 
 	// Track running task
 	o.runningMutex.Lock()
 	o.runningTasks[repo.Name] = cmd
 	o.runningMutex.Unlock()
 
-	// Run command
-	startTime := time.Now()
-	err = cmd.Run()
-	duration := time.Since(startTime)
-
-	// Remove from running tasks
-	o.runningMutex.Lock()
-	delete(o.runningTasks, repo.Name)
-	o.runningMutex.Unlock()
+	o.progress.start(repo.Name, gear)
+	o.progressSink.OnGearStart(repo.Name, gear)
 
-	if err != nil {
+	// Run command, preempting it if it crosses its time or token budget, or
+	// the run is cancelled out from under it.
+	startTime := time.Now()
+	if startErr := cmd.Start(); startErr != nil {
+		o.runningMutex.Lock()
+		delete(o.runningTasks, repo.Name)
+		o.runningMutex.Unlock()
 		return GearResult{
 			Repo:    repo.Name,
 			Gear:    gear,
 			Success: false,
-			Message: fmt.Sprintf("Command failed after %s: %v", duration, err),
+			Message: fmt.Sprintf("Failed to start command: %v", startErr),
 		}
 	}
 
-	// Validate completion
-	success, message := o.validateGear(repo, gear)
+	waitDone := make(chan error, 1)
+	go func() { waitDone <- cmd.Wait() }()
 
-	return GearResult{
-		Repo:    repo.Name,
-		Gear:    gear,
-		Success: success,
-		Message: message,
+	var timeoutCh <-chan time.Time
+	if budget.MaxDuration > 0 {
+		timer := time.NewTimer(budget.MaxDuration)
+		defer timer.Stop()
+		timeoutCh = timer.C
 	}
-}
-
-func (o *Orchestrator) buildClaudeCodeCommand(repo Repository, gearName string) *exec.Cmd {
-	// Build command to execute StackShift skill using Claude Code
-	// The skills are accessed via the slash commands in the plugin
 
-	// Map gear names to skill commands
-	skillCommands := map[string]string{
-		"analyze":          "/stackshift:analyze",
-		"reverse-engineer": "/stackshift:reverse-engineer",
-		"create-specs":     "/stackshift:create-specs",
-		"gap-analysis":     "/stackshift:gap-analysis",
-		"complete-spec":    "/stackshift:complete-specs",
-		"implement":        "/stackshift:implement",
+	var budgetReason string
+	var cancelled bool
+	select {
+	case err = <-waitDone:
+	case <-timeoutCh:
+		budgetReason = fmt.Sprintf("BudgetExceeded: gear exceeded max duration %s", budget.MaxDuration)
+	case <-budgetTokenCounter.exceeded:
+		budgetReason = fmt.Sprintf("BudgetExceeded: gear exceeded max tokens %d", budget.MaxTokens)
+	case <-o.rootCtx.Done():
+		cancelled = true
 	}
 
-	skillCommand, exists := skillCommands[gearName]
-	if !exists {
-		// Fallback to basic prompt
-		prompt := fmt.Sprintf("Execute StackShift %s gear for this repository", gearName)
-		return exec.Command("claude", prompt)
+	if budgetReason != "" || cancelled {
+		if cancelled || budget.policy() == BudgetKill {
+			killProcessGroup(cmd)
+		}
+		err = <-waitDone
 	}
 
-	// Build the command with the skill invocation
-	prompt := fmt.Sprintf("Execute the StackShift skill: %s\n\nRepository: %s\nPath: %s\nLanguage: %s\nFramework: %s\n\nSettings:\n- Route: %s\n- Clarification: %s\n- Implementation: %s",
-		skillCommand,
-		repo.Name,
-		repo.Path,
-		repo.Language,
-		repo.Framework,
-		o.settings.Route,
-		o.settings.Clarification,
-		o.settings.Implementation,
-	)
-
-	// Use claude CLI with the prompt
-	return exec.Command("claude", prompt)
-}
-
-func (o *Orchestrator) buildOpenCodeCommand(repo Repository, gearName string) *exec.Cmd {
-	// For OpenCode/VSCode with CoPilot, we'll create a prompt file
-	// that can be executed via code CLI or similar tool
-
-	// Generate gear-specific prompt content
-	promptContent := o.generateGearPrompt(gearName, repo)
+	duration := time.Since(startTime)
 
-	// Create temp file with the prompt
-	tmpFile := filepath.Join(os.TempDir(), fmt.Sprintf("stackshift_%s_%s.md", repo.Name, gearName))
-	os.WriteFile(tmpFile, []byte(promptContent), 0644)
+	// Remove from running tasks
+	o.runningMutex.Lock()
+	delete(o.runningTasks, repo.Name)
+	o.runningMutex.Unlock()
 
-	// Check if 'code' CLI is available (VSCode)
-	if _, err := exec.LookPath("code"); err == nil {
-		// Use VSCode CLI to open the prompt file and repository
-		return exec.Command("code", repo.Path, tmpFile)
+	if cancelled {
+		return GearResult{
+			Repo:       repo.Name,
+			Gear:       gear,
+			Success:    false,
+			Message:    "Cancelled: run was stopped",
+			StderrTail: stderrTail.String(),
+		}
 	}
 
-	// Fallback to generic command - user may need to configure
-	return exec.Command("echo", fmt.Sprintf("Please run StackShift %s gear for %s manually", gearName, repo.Name))
-}
-
-func (o *Orchestrator) generateGearPrompt(gearName string, repo Repository) string {
-	// Generate gear-specific prompts for manual execution
-	prompts := map[string]string{
-		"analyze": `# StackShift Gear 1: Analyze
-
-Please analyze this repository and create an analysis-report.md file containing:
-1. Technology stack analysis
-2. Architecture overview
-3. Dependencies and integrations
-4. Key business logic components
-5. Database schema and data flow`,
-
-		"reverse-engineer": `# StackShift Gear 2: Reverse Engineer
-
-Create comprehensive documentation in docs/reverse-engineering/ including:
-- functional-specification.md
-- data-architecture.md
-- configuration-reference.md
-- api-documentation.md`,
-
-		"create-specs": `# StackShift Gear 3: Create Specs
-
-Based on the analysis, create specifications in docs/specs/ for:
-- Technical requirements
-- API contracts
-- Data models
-- Business rules`,
+	if budgetReason != "" && budget.policy() == BudgetKill {
+		return GearResult{
+			Repo:       repo.Name,
+			Gear:       gear,
+			Success:    false,
+			Message:    budgetReason,
+			StderrTail: stderrTail.String(),
+		}
+	}
 
-		"gap-analysis": `# StackShift Gear 4: Gap Analysis
+	if err != nil {
+		exitCode := -1
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			exitCode = exitErr.ExitCode()
+		}
+		return GearResult{
+			Repo:       repo.Name,
+			Gear:       gear,
+			Success:    false,
+			Message:    fmt.Sprintf("Command failed after %s: %v", duration, err),
+			ExitCode:   exitCode,
+			StderrTail: stderrTail.String(),
+		}
+	}
 
-Analyze gaps between current implementation and target stack.
-Create gap-analysis.md documenting:
-- Missing features
-- Architecture differences
-- Migration challenges`,
+	// Validate completion
+	success, message, validationErr := o.validateGear(repo, gear)
 
-		"complete-spec": `# StackShift Gear 5: Complete Specifications
+	if success && cacheKey != "" {
+		o.populateCache(repo, gearName, cacheKey)
+	}
 
-Finalize all specifications with:
-- Implementation details
-- Migration strategy
-- Testing requirements
-- Deployment plan`,
+	if budgetReason != "" {
+		// Policy was "warn" or "continue": note the overrun but don't fail the gear.
+		message = fmt.Sprintf("%s (%s)", message, budgetReason)
+	}
 
-		"implement": `# StackShift Gear 6: Implement
+	result := GearResult{
+		Repo:    repo.Name,
+		Gear:    gear,
+		Success: success,
+		Message: message,
+	}
+	if validationErr != nil {
+		result.StderrTail = stderrTail.String()
+	}
+	return result
+}
 
-Begin implementation based on specifications:
-- Create new components
-- Migrate existing functionality
-- Update tests
-- Document changes`,
+// recordTaskSummary appends this gear's entry to the run's RunSummary and,
+// if remote reporting is configured, PATCHes it to Spaces. Called by runGear
+// after every attempt, so each attempt, including early failures before the
+// backend ever starts, gets its own entry.
+func (o *Orchestrator) recordTaskSummary(repo Repository, gear int, gearName, logFile string, start time.Time, result GearResult) {
+	end := time.Now()
+	task := TaskSummary{
+		TaskID:     fmt.Sprintf("%s#%s", repo.Name, gearName),
+		Repo:       repo.Name,
+		GearName:   gearName,
+		StartTime:  start,
+		EndTime:    end,
+		DurationMs: end.Sub(start).Milliseconds(),
+		ExitCode:   result.ExitCode,
+		Success:    result.Success,
+		Cached:     result.Cached,
+		LogFile:    logFile,
+		InputHash:  hashPrompt([]byte(renderMarkdownPrompt(gearName, repo, o.effectiveSettings(repo)))),
 	}
 
-	basePrompt := prompts[gearName]
-	if basePrompt == "" {
-		basePrompt = fmt.Sprintf("# StackShift Gear: %s\n\nExecute the %s gear for this repository.", gearName, gearName)
+	if o.summary != nil {
+		o.summary.addTask(task)
+	}
+	if o.spaces != nil && !o.noReport {
+		o.spaces.reportTask(o.runID, task)
 	}
+}
 
-	// Add context
-	return fmt.Sprintf(`%s
+// stderrTailWriter keeps the last stderrTailBytes of a command's stderr, so
+// a failed gear's GearResult can surface a short excerpt without having to
+// re-read the full log file.
+const stderrTailBytes = 4096
 
-Repository: %s
-Path: %s
-Language: %s
-Framework: %s
+type stderrTailWriter struct {
+	buf []byte
+}
 
-Settings:
-- Route: %s
-- Clarification Strategy: %s
-- Implementation Scope: %s
+func (w *stderrTailWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	if len(w.buf) > stderrTailBytes {
+		w.buf = w.buf[len(w.buf)-stderrTailBytes:]
+	}
+	return len(p), nil
+}
 
-Please execute this gear and update .stackshift-state.json when complete.`,
-		basePrompt,
-		repo.Name,
-		repo.Path,
-		repo.Language,
-		repo.Framework,
-		o.settings.Route,
-		o.settings.Clarification,
-		o.settings.Implementation,
-	)
+func (w *stderrTailWriter) String() string {
+	return string(w.buf)
 }
 
 func getGearNumber(gearName string) int {
 	gears := map[string]int{
-		"analyze":           1,
-		"reverse-engineer":  2,
-		"create-specs":      3,
-		"gap-analysis":      4,
-		"complete-spec":     5,
-		"implement":         6,
+		"analyze":          1,
+		"reverse-engineer": 2,
+		"create-specs":     3,
+		"gap-analysis":     4,
+		"complete-spec":    5,
+		"implement":        6,
 	}
 	return gears[gearName]
 }
 
-func (o *Orchestrator) validateGear(repo Repository, gear int) (bool, string) {
-	stateFile := filepath.Join(repo.Path, ".stackshift-state.json")
-
-	// Check if state file exists
-	data, err := os.ReadFile(stateFile)
+// validateGear confirms a gear actually completed by checking the repo's
+// .stackshift-state.json, distinguishing why it might not have (missing
+// file, malformed JSON, or simply not done yet) so callers can decide how
+// to react to each case differently.
+func (o *Orchestrator) validateGear(repo Repository, gear int) (bool, string, *ValidationError) {
+	state, err := loadStateFile(repo.Path)
 	if err != nil {
-		return false, "State file not found"
-	}
-
-	var state StateFile
-	if err := json.Unmarshal(data, &state); err != nil {
-		return false, "Invalid state file"
-	}
-
-	// Check if gear is in completed steps
-	gearNames := map[int]string{
-		1: "analyze",
-		2: "reverse-engineer",
-		3: "create-specs",
-		4: "gap-analysis",
-		5: "complete-spec",
-		6: "implement",
+		if os.IsNotExist(err) {
+			verr := &ValidationError{Reason: ValidationMissingStateFile, Detail: err.Error()}
+			return false, "State file not found", verr
+		}
+		verr := &ValidationError{Reason: ValidationMalformedStateFile, Detail: err.Error()}
+		return false, "Invalid state file", verr
 	}
 
 	expectedStep := gearNames[gear]
 	for _, step := range state.CompletedSteps {
 		if step == expectedStep {
-			return true, "Gear completed successfully"
+			return true, "Gear completed successfully", nil
 		}
 	}
 
-	return false, fmt.Sprintf("Gear %s not found in completed steps", expectedStep)
+	verr := &ValidationError{Reason: ValidationGearNotComplete, Detail: expectedStep}
+	return false, fmt.Sprintf("Gear %s not found in completed steps", expectedStep), verr
 }
 
 func (o *Orchestrator) validateGear1(repo Repository) (bool, string) {
@@ -419,33 +862,49 @@ func (o *Orchestrator) validateGear2(repo Repository) (bool, string) {
 	return true, fmt.Sprintf("Reverse engineering complete (%d/3 docs)", foundCount)
 }
 
+// Kill terminates repoName's in-flight gear, process group and all, so a
+// wrapper script or sub-shell the backend spawned doesn't outlive it.
 func (o *Orchestrator) Kill(repoName string) error {
 	o.runningMutex.Lock()
 	defer o.runningMutex.Unlock()
 
 	if cmd, exists := o.runningTasks[repoName]; exists {
-		if cmd.Process != nil {
-			return cmd.Process.Kill()
-		}
+		killProcessGroup(cmd)
 	}
 
 	return nil
 }
 
+// KillAll requests a graceful shutdown: no new gears are scheduled, and
+// in-flight ones are given a grace period to finish (and flush their
+// manifest entry) before being hard-killed, process group and all. Safe to
+// call from a SIGINT handler.
 func (o *Orchestrator) KillAll() {
+	o.stopOnce.Do(func() {
+		close(o.stop)
+	})
+
+	select {
+	case <-o.shutdownPending:
+		return
+	case <-time.After(10 * time.Second):
+	}
+
+	// The grace period elapsed without every gear finishing on its own:
+	// cancel rootCtx so runGearAttempt's select sees it immediately, then
+	// hard-kill whatever's still running, process group and all.
+	o.cancelRoot()
+
 	o.runningMutex.Lock()
 	defer o.runningMutex.Unlock()
 
 	for _, cmd := range o.runningTasks {
-		if cmd.Process != nil {
-			cmd.Process.Kill()
-		}
+		killProcessGroup(cmd)
 	}
 }
 
+// GetProgress reports a repo's current gear and status as tracked live by
+// runGear, independent of any configured ProgressSink.
 func (o *Orchestrator) GetProgress(repoName string) (int, string) {
-	// Read state file from repo
-	// Return current gear and status
-	// This would parse .stackshift-state.json
-	return 0, "in_progress"
+	return o.progress.get(repoName)
 }
@@ -0,0 +1,30 @@
+//go:build windows
+
+package main
+
+import (
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// configureProcessGroup puts cmd in its own process group (via
+// CREATE_NEW_PROCESS_GROUP) so killProcessGroup can tear down it and any
+// children it spawns in one call, instead of leaking orphans when a gear
+// times out or a run is cancelled.
+func configureProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags = syscall.CREATE_NEW_PROCESS_GROUP
+}
+
+// killProcessGroup terminates cmd's whole process tree. Windows has no
+// single-call equivalent of Unix's negative-PID kill, so this shells out to
+// taskkill /T, which kills the process and everything under it.
+func killProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process == nil {
+		return
+	}
+	exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run()
+}
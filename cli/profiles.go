@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// Profile is a named execution preset from ~/.stackshift/profiles.yaml, so a
+// team can standardize a migration playbook (say, "fast" for local
+// iteration vs "thorough" for a full overnight run) instead of every
+// invocation having to repeat the same handful of flags.
+type Profile struct {
+	Name           string            `yaml:"-"`
+	Route          string            `yaml:"route"`
+	Transmission   string            `yaml:"transmission"`
+	Clarification  string            `yaml:"clarification"`
+	Implementation string            `yaml:"implementation"`
+	ParallelLimit  int               `yaml:"parallelLimit"`
+	Backend        string            `yaml:"backend"`
+	OnlyGears      []string          `yaml:"onlyGears"`
+	Budgets        map[string]Budget `yaml:"budgets"`
+}
+
+// profilesFile is the on-disk shape of ~/.stackshift/profiles.yaml.
+type profilesFile struct {
+	Default  string             `yaml:"default"`
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+func profilesConfigPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".stackshift", "profiles.yaml"), nil
+}
+
+// loadProfilesFile reads profiles.yaml, returning a zero-valued file (not an
+// error) if it doesn't exist yet.
+func loadProfilesFile() (profilesFile, error) {
+	path, err := profilesConfigPath()
+	if err != nil {
+		return profilesFile{}, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return profilesFile{}, nil
+		}
+		return profilesFile{}, err
+	}
+
+	var pf profilesFile
+	if err := yaml.Unmarshal(data, &pf); err != nil {
+		return profilesFile{}, err
+	}
+	return pf, nil
+}
+
+// saveProfilesFile atomically writes pf to profiles.yaml.
+func saveProfilesFile(pf profilesFile) error {
+	path, err := profilesConfigPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(pf)
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// resolveProfile looks up name in profiles.yaml, falling back to the file's
+// own default profile when name is empty. Returns a zero-valued Profile
+// (Name == "") if neither is set, which applyProfileDefaults treats as "no
+// profile" rather than an error.
+func resolveProfile(name string) (Profile, error) {
+	pf, err := loadProfilesFile()
+	if err != nil {
+		return Profile{}, err
+	}
+
+	if name == "" {
+		name = pf.Default
+	}
+	if name == "" {
+		return Profile{}, nil
+	}
+
+	p, ok := pf.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown profile %q", name)
+	}
+	p.Name = name
+	return p, nil
+}
+
+// applyProfileDefaults fills in any flag the user didn't pass explicitly
+// from profile, the same way applyConfigDefaults does for
+// ~/.config/stackshift/config.toml — except a profile takes priority over
+// that file, since picking one by name is a more deliberate choice than
+// whatever's saved as the global default. Merge order end to end is CLI
+// flags > profile > config.toml > flag defaults.
+func applyProfileDefaults(flags *pflag.FlagSet, profile Profile) {
+	if profile.Name == "" {
+		return
+	}
+
+	setString := func(name string, dst *string, value string) {
+		if value != "" && !flags.Changed(name) {
+			*dst = value
+		}
+	}
+
+	setString("route", &flagRoute, profile.Route)
+	setString("transmission", &flagTransmission, profile.Transmission)
+	setString("clarification", &flagClarification, profile.Clarification)
+	setString("implementation", &flagImplementation, profile.Implementation)
+	setString("backend", &flagBackend, profile.Backend)
+
+	if profile.ParallelLimit > 0 && !flags.Changed("parallel") {
+		flagParallel = profile.ParallelLimit
+	}
+	if len(profile.OnlyGears) > 0 && !flags.Changed("only") {
+		flagOnly = strings.Join(profile.OnlyGears, ",")
+	}
+}
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage named execution profiles in ~/.stackshift/profiles.yaml",
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List every defined profile",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pf, err := loadProfilesFile()
+		if err != nil {
+			return err
+		}
+
+		if len(pf.Profiles) == 0 {
+			fmt.Println("No profiles defined. See ~/.stackshift/profiles.yaml.")
+			return nil
+		}
+
+		names := make([]string, 0, len(pf.Profiles))
+		for name := range pf.Profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			marker := "  "
+			if name == pf.Default {
+				marker = "* "
+			}
+			fmt.Printf("%s%s\n", marker, name)
+		}
+		return nil
+	},
+}
+
+var profileShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Print one profile's resolved settings",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile, err := resolveProfile(args[0])
+		if err != nil {
+			return err
+		}
+
+		data, err := yaml.Marshal(profile)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(data))
+		return nil
+	},
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Set name as the default profile for future runs",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pf, err := loadProfilesFile()
+		if err != nil {
+			return err
+		}
+
+		if _, ok := pf.Profiles[args[0]]; !ok {
+			return fmt.Errorf("unknown profile %q", args[0])
+		}
+
+		pf.Default = args[0]
+		if err := saveProfilesFile(pf); err != nil {
+			return err
+		}
+
+		fmt.Printf("Default profile set to %q\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	profileCmd.AddCommand(profileListCmd, profileShowCmd, profileUseCmd)
+	rootCmd.AddCommand(profileCmd)
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ProgressSink receives live events as gears run, so a migration across
+// dozens of repos can be monitored centrally instead of the user having to
+// `tail -f` dozens of per-gear log files.
+type ProgressSink interface {
+	OnGearStart(repo string, gear int)
+	OnGearLog(repo string, gear int, chunk []byte)
+	OnGearComplete(repo string, gear int, result GearResult)
+	OnRunComplete(results []GearResult)
+}
+
+// multiSink fans every event out to a list of sinks, so the orchestrator
+// itself only ever has to know about one sink.
+type multiSink struct {
+	sinks []ProgressSink
+}
+
+func (m *multiSink) OnGearStart(repo string, gear int) {
+	for _, s := range m.sinks {
+		s.OnGearStart(repo, gear)
+	}
+}
+
+func (m *multiSink) OnGearLog(repo string, gear int, chunk []byte) {
+	for _, s := range m.sinks {
+		s.OnGearLog(repo, gear, chunk)
+	}
+}
+
+func (m *multiSink) OnGearComplete(repo string, gear int, result GearResult) {
+	for _, s := range m.sinks {
+		s.OnGearComplete(repo, gear, result)
+	}
+}
+
+func (m *multiSink) OnRunComplete(results []GearResult) {
+	for _, s := range m.sinks {
+		s.OnRunComplete(results)
+	}
+}
+
+// sinkWriter adapts a ProgressSink into an io.Writer so it can sit inside
+// an io.MultiWriter alongside the per-gear log file.
+type sinkWriter struct {
+	sink ProgressSink
+	repo string
+	gear int
+}
+
+func (w sinkWriter) Write(p []byte) (int, error) {
+	w.sink.OnGearLog(w.repo, w.gear, p)
+	return len(p), nil
+}
+
+// FileProgressSink appends one NDJSON line per event to <resultsDir>/events.ndjson.
+type FileProgressSink struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileProgressSink returns a sink for the events log under resultsDir; the
+// file is opened (and created if needed) on each event, not here.
+func NewFileProgressSink(resultsDir string) *FileProgressSink {
+	return &FileProgressSink{path: filepath.Join(resultsDir, "events.ndjson")}
+}
+
+func (f *FileProgressSink) append(event map[string]any) {
+	event["time"] = time.Now().Format(time.RFC3339Nano)
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	file.Write(append(line, '\n'))
+}
+
+func (f *FileProgressSink) OnGearStart(repo string, gear int) {
+	f.append(map[string]any{"event": "gear_start", "repo": repo, "gear": gear})
+}
+
+func (f *FileProgressSink) OnGearLog(repo string, gear int, chunk []byte) {
+	// Log chunks aren't persisted to the NDJSON stream; the per-gear log
+	// file under resultsDir already holds full stdout/stderr.
+}
+
+func (f *FileProgressSink) OnGearComplete(repo string, gear int, result GearResult) {
+	f.append(map[string]any{
+		"event":   "gear_complete",
+		"repo":    repo,
+		"gear":    gear,
+		"success": result.Success,
+		"message": result.Message,
+	})
+}
+
+func (f *FileProgressSink) OnRunComplete(results []GearResult) {
+	f.append(map[string]any{"event": "run_complete", "gears": len(results)})
+}
+
+// HTTPProgressSink POSTs each event as JSON to a remote dashboard endpoint,
+// retrying transient failures with exponential backoff so a blip in the
+// dashboard's availability doesn't abort the run.
+type HTTPProgressSink struct {
+	Endpoint    string
+	BearerToken string
+	MaxRetries  int
+	Client      *http.Client
+}
+
+// NewHTTPProgressSink builds a sink with sane retry defaults.
+func NewHTTPProgressSink(endpoint, bearerToken string) *HTTPProgressSink {
+	return &HTTPProgressSink{
+		Endpoint:    endpoint,
+		BearerToken: bearerToken,
+		MaxRetries:  3,
+		Client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (h *HTTPProgressSink) post(event map[string]any) {
+	event["time"] = time.Now().Format(time.RFC3339Nano)
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	sendWithRetry(h.Client, http.MethodPost, h.Endpoint, h.BearerToken, body, h.MaxRetries)
+}
+
+// sendWithRetry sends body to url with method, retrying transient failures
+// (request errors and 5xx responses) with exponential backoff before giving
+// up and dropping the payload. Shared by HTTPProgressSink and SpacesConfig,
+// the two places stackshift reports over the network rather than to disk.
+func sendWithRetry(client *http.Client, method, url, bearerToken string, body []byte, maxRetries int) {
+	backoff := 500 * time.Millisecond
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		req, err := http.NewRequest(method, url, bytes.NewReader(body))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/json")
+			if bearerToken != "" {
+				req.Header.Set("Authorization", "Bearer "+bearerToken)
+			}
+
+			resp, err := client.Do(req)
+			if err == nil {
+				resp.Body.Close()
+				if resp.StatusCode < 500 {
+					return
+				}
+			}
+		}
+
+		if attempt < maxRetries {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	// All retries exhausted; drop the payload rather than abort the run.
+}
+
+func (h *HTTPProgressSink) OnGearStart(repo string, gear int) {
+	go h.post(map[string]any{"event": "gear_start", "repo": repo, "gear": gear})
+}
+
+func (h *HTTPProgressSink) OnGearLog(repo string, gear int, chunk []byte) {
+	go h.post(map[string]any{"event": "gear_log", "repo": repo, "gear": gear, "chunk": string(chunk)})
+}
+
+func (h *HTTPProgressSink) OnGearComplete(repo string, gear int, result GearResult) {
+	go h.post(map[string]any{
+		"event":   "gear_complete",
+		"repo":    repo,
+		"gear":    gear,
+		"success": result.Success,
+		"message": result.Message,
+	})
+}
+
+func (h *HTTPProgressSink) OnRunComplete(results []GearResult) {
+	go h.post(map[string]any{"event": "run_complete", "gears": len(results)})
+}
+
+// StdoutProgressSink prints gear events to stdout for --headless runs, as
+// either human-readable lines or NDJSON when JSON is set, so the tool is
+// scriptable from CI without a terminal attached.
+type StdoutProgressSink struct {
+	JSON bool
+}
+
+func (s *StdoutProgressSink) emit(event map[string]any) {
+	event["time"] = time.Now().Format(time.RFC3339Nano)
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	fmt.Println(string(line))
+}
+
+func (s *StdoutProgressSink) OnGearStart(repo string, gear int) {
+	if s.JSON {
+		s.emit(map[string]any{"event": "gear_start", "repo": repo, "gear": gear})
+		return
+	}
+	fmt.Printf("-> %s gear %d: starting\n", repo, gear)
+}
+
+func (s *StdoutProgressSink) OnGearLog(repo string, gear int, chunk []byte) {
+	// Raw gear output isn't echoed to stdout; the per-gear log file under
+	// resultsDir already holds it, same as FileProgressSink.
+}
+
+func (s *StdoutProgressSink) OnGearComplete(repo string, gear int, result GearResult) {
+	if s.JSON {
+		s.emit(map[string]any{
+			"event":   "gear_complete",
+			"repo":    repo,
+			"gear":    gear,
+			"success": result.Success,
+			"message": result.Message,
+		})
+		return
+	}
+
+	status := "ok"
+	if !result.Success {
+		status = "FAILED: " + result.Message
+	}
+	fmt.Printf("-> %s gear %d: %s\n", repo, gear, status)
+}
+
+func (s *StdoutProgressSink) OnRunComplete(results []GearResult) {
+	failed := 0
+	for _, r := range results {
+		if !r.Success {
+			failed++
+		}
+	}
+
+	if s.JSON {
+		s.emit(map[string]any{"event": "run_complete", "gears": len(results), "failed": failed})
+		return
+	}
+	fmt.Printf("Run complete: %d gears, %d failed\n", len(results), failed)
+}
+
+// repoProgress is the orchestrator's own bookkeeping of each repo's current
+// gear/status, independent of whatever external sinks are attached. This is
+// what GetProgress reports from.
+type repoProgress struct {
+	gear   int
+	status string // "in_progress", "complete", "failed"
+}
+
+type progressTracker struct {
+	mu    sync.Mutex
+	repos map[string]repoProgress
+}
+
+func newProgressTracker() *progressTracker {
+	return &progressTracker{repos: map[string]repoProgress{}}
+}
+
+func (t *progressTracker) start(repo string, gear int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.repos[repo] = repoProgress{gear: gear, status: "in_progress"}
+}
+
+func (t *progressTracker) complete(repo string, gear int, success bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	status := "complete"
+	if !success {
+		status = "failed"
+	}
+	t.repos[repo] = repoProgress{gear: gear, status: status}
+}
+
+func (t *progressTracker) get(repo string) (int, string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, ok := t.repos[repo]
+	if !ok {
+		return 0, "not_started"
+	}
+	return p.gear, p.status
+}
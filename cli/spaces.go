@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// SpacesConfig reports a run's progress to a remote Spaces endpoint: a POST
+// when the run starts, a PATCH per finished task, and a PATCH once the run
+// completes. This lets a dashboard show live migration status across dozens
+// of repos without polling stackshift's own results directory, the same
+// purpose HTTPProgressSink serves for per-gear events.
+type SpacesConfig struct {
+	URL         string
+	BearerToken string
+	MaxRetries  int
+	Client      *http.Client
+}
+
+// NewSpacesConfig builds a SpacesConfig with sane retry defaults.
+func NewSpacesConfig(url, bearerToken string) *SpacesConfig {
+	return &SpacesConfig{
+		URL:         url,
+		BearerToken: bearerToken,
+		MaxRetries:  3,
+		Client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// reportRunStart POSTs the run's identity before the batch loop begins.
+func (s *SpacesConfig) reportRunStart(summary *RunSummary) {
+	body, err := json.Marshal(map[string]any{
+		"event":    "run_started",
+		"id":       summary.ID,
+		"command":  summary.Command,
+		"repoPath": summary.RepoPath,
+		"time":     time.Now().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return
+	}
+	go sendWithRetry(s.Client, http.MethodPost, s.URL, s.BearerToken, body, s.MaxRetries)
+}
+
+// reportTask PATCHes a single task's entry as soon as runGear finishes it.
+func (s *SpacesConfig) reportTask(runID string, task TaskSummary) {
+	body, err := json.Marshal(map[string]any{
+		"event": "task_updated",
+		"runId": runID,
+		"task":  task,
+		"time":  time.Now().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return
+	}
+	go sendWithRetry(s.Client, http.MethodPatch, s.URL, s.BearerToken, body, s.MaxRetries)
+}
+
+// reportRunFinish PATCHes the run's final outcome once every gear has run.
+func (s *SpacesConfig) reportRunFinish(summary *RunSummary) {
+	body, err := json.Marshal(map[string]any{
+		"event":     "run_finished",
+		"id":        summary.ID,
+		"success":   summary.Success,
+		"attempted": summary.Attempted,
+		"failed":    summary.Failed,
+		"cached":    summary.Cached,
+		"time":      time.Now().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return
+	}
+	go sendWithRetry(s.Client, http.MethodPatch, s.URL, s.BearerToken, body, s.MaxRetries)
+}
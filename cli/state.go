@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// stateSchemaVersion is the current .stackshift-state.json shape. Bump this
+// and add a case to migrateStateFile whenever the schema changes, so repos
+// left mid-run by an older stackshift still read back cleanly.
+const stateSchemaVersion = 2
+
+// StateFile is a repo's .stackshift-state.json: its own record of which
+// gear it's on and what's already been done. Gears write it themselves (see
+// renderMarkdownPrompt); stackshift only ever reads it back.
+type StateFile struct {
+	SchemaVersion    int      `json:"schemaVersion"`
+	CurrentGear      int      `json:"currentGear"`
+	CompletedSteps   []string `json:"completedSteps"`
+	LastRunAt        string   `json:"lastRunAt"`
+	SettingsSnapshot Settings `json:"settingsSnapshot"`
+	Artifacts        []string `json:"artifacts"`
+}
+
+// legacyStateFileV0 is the pre-schemaVersion shape (no schemaVersion field,
+// steps identified by name rather than gear number).
+type legacyStateFileV0 struct {
+	Version        string   `json:"version"`
+	Created        string   `json:"created"`
+	Updated        string   `json:"updated"`
+	Path           string   `json:"path"`
+	CurrentStep    string   `json:"currentStep"`
+	CompletedSteps []string `json:"completedSteps"`
+	CruiseControl  struct {
+		Enabled               bool   `json:"enabled"`
+		ClarificationStrategy string `json:"clarificationStrategy"`
+		ImplementationScope   string `json:"implementationScope"`
+		AutoMode              bool   `json:"autoMode"`
+	} `json:"cruiseControl"`
+}
+
+// loadStateFile reads and migrates a repo's .stackshift-state.json.
+func loadStateFile(repoPath string) (*StateFile, error) {
+	data, err := os.ReadFile(filepath.Join(repoPath, ".stackshift-state.json"))
+	if err != nil {
+		return nil, err
+	}
+	return parseStateFile(data)
+}
+
+// parseStateFile migrates data forward to stateSchemaVersion before
+// unmarshaling it, based on whatever schemaVersion (or lack of one) it
+// declares.
+func parseStateFile(data []byte) (*StateFile, error) {
+	var probe struct {
+		SchemaVersion int `json:"schemaVersion"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, err
+	}
+
+	if probe.SchemaVersion >= stateSchemaVersion {
+		var state StateFile
+		if err := json.Unmarshal(data, &state); err != nil {
+			return nil, err
+		}
+		return &state, nil
+	}
+
+	return migrateStateFile(probe.SchemaVersion, data)
+}
+
+// migrateStateFile upgrades a state file from an older schemaVersion to
+// stateSchemaVersion. Schema 0 is the original {version, currentStep, ...}
+// shape written before schemaVersion existed at all.
+func migrateStateFile(fromVersion int, data []byte) (*StateFile, error) {
+	switch fromVersion {
+	case 0:
+		var legacy legacyStateFileV0
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return nil, err
+		}
+		return &StateFile{
+			SchemaVersion:  stateSchemaVersion,
+			CurrentGear:    getGearNumber(legacy.CurrentStep),
+			CompletedSteps: legacy.CompletedSteps,
+			LastRunAt:      legacy.Updated,
+		}, nil
+	default:
+		var state StateFile
+		if err := json.Unmarshal(data, &state); err != nil {
+			return nil, err
+		}
+		state.SchemaVersion = stateSchemaVersion
+		return &state, nil
+	}
+}
+
+// checkProgress reports a repo's current gear and status by reading its
+// .stackshift-state.json, falling back to "not_started" when the file is
+// missing, empty, or malformed.
+func checkProgress(repoPath string) (int, string) {
+	state, err := loadStateFile(repoPath)
+	if err != nil {
+		return 0, "not_started"
+	}
+
+	switch {
+	case len(state.CompletedSteps) >= len(gearNames):
+		return state.CurrentGear, "complete"
+	case len(state.CompletedSteps) > 0:
+		return state.CurrentGear, "in_progress"
+	default:
+		return state.CurrentGear, "not_started"
+	}
+}
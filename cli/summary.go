@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// TaskSummary is one (repo, gear) entry in a RunSummary: enough to answer
+// "what ran, how long did it take, and did it succeed" without re-reading
+// the gear's log file.
+type TaskSummary struct {
+	TaskID     string    `json:"taskId"`
+	Repo       string    `json:"repo"`
+	GearName   string    `json:"gearName"`
+	StartTime  time.Time `json:"startTime"`
+	EndTime    time.Time `json:"endTime"`
+	DurationMs int64     `json:"durationMs"`
+	ExitCode   int       `json:"exitCode"`
+	Success    bool      `json:"success"`
+	Cached     bool      `json:"cached"`
+	LogFile    string    `json:"logFile"`
+	InputHash  string    `json:"inputHash"`
+}
+
+// RunSummary is the full record of one orchestrator invocation, written to
+// <resultsDir>/run-summary.json once the run finishes, the same role
+// Turborepo's runSummary.json plays for a `turbo run`.
+type RunSummary struct {
+	ID        string        `json:"id"`
+	Command   string        `json:"command"`
+	RepoPath  string        `json:"repoPath"`
+	StartTime time.Time     `json:"startTime"`
+	EndTime   time.Time     `json:"endTime"`
+	ExitCode  int           `json:"exitCode"`
+	Success   bool          `json:"success"`
+	Attempted int           `json:"attempted"`
+	Failed    int           `json:"failed"`
+	Cached    int           `json:"cached"`
+	Tasks     []TaskSummary `json:"tasks"`
+	Profile   *Profile      `json:"profile,omitempty"`
+
+	mu sync.Mutex
+}
+
+// newRunSummary starts a RunSummary for a fresh invocation. command and
+// repoPath are recorded as-given so a dashboard reading run-summary.json
+// later can show what was actually run without re-deriving it from flags.
+func newRunSummary(runID, command, repoPath string) *RunSummary {
+	return &RunSummary{
+		ID:        runID,
+		Command:   command,
+		RepoPath:  repoPath,
+		StartTime: time.Now(),
+	}
+}
+
+// addTask appends a completed task's entry. Safe for concurrent callers,
+// since runGear runs inside runGraph's worker pool.
+func (s *RunSummary) addTask(task TaskSummary) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Tasks = append(s.Tasks, task)
+}
+
+// snapshot returns a copy of task entries recorded so far, for callers that
+// need to read them without holding the summary's lock themselves.
+func (s *RunSummary) snapshot() []TaskSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tasks := make([]TaskSummary, len(s.Tasks))
+	copy(tasks, s.Tasks)
+	return tasks
+}
+
+// finish stamps the overall outcome once every gear has run and atomically
+// writes the summary to <resultsDir>/run-summary.json.
+func (s *RunSummary) finish(resultsDir string, results []GearResult) error {
+	s.mu.Lock()
+	s.EndTime = time.Now()
+	s.Attempted = len(results)
+	for _, r := range results {
+		if !r.Success {
+			s.Failed++
+		}
+	}
+	for _, t := range s.Tasks {
+		if t.Cached {
+			s.Cached++
+		}
+	}
+	s.Success = s.Failed == 0
+	if !s.Success {
+		s.ExitCode = 1
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(resultsDir, "run-summary.json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagSupportOutput string
+	flagSupportRedact bool
+)
+
+var supportCmd = &cobra.Command{
+	Use:   "support",
+	Short: "Diagnostics for reporting stackshift issues",
+}
+
+var supportDumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Bundle logs, repo state, and environment info into a zip a maintainer can debug from",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSupportDump(flagSupportOutput, flagSupportRedact)
+	},
+}
+
+func init() {
+	flags := supportDumpCmd.Flags()
+	flags.StringVar(&flagSupportOutput, "output", "", "zip file to write (default stackshift-support-<timestamp>.zip); use - for stdout")
+	flags.BoolVar(&flagSupportRedact, "redact", false, "strip absolute home paths and repo URLs from the bundle")
+
+	supportCmd.AddCommand(supportDumpCmd)
+	rootCmd.AddCommand(supportCmd)
+}
+
+// runSupportDump gathers everything a maintainer needs to debug a failed
+// run (gear logs, every discovered repo's state file, the effective
+// settings, every registered backend's name and CLI version, and a per-gear
+// validation manifest) into a single zip written to output, or stdout if
+// output is "-". Which backend a run would resolve to is not recorded.
+func runSupportDump(output string, redact bool) error {
+	var w io.Writer
+	if output == "-" {
+		w = os.Stdout
+	} else {
+		if output == "" {
+			output = fmt.Sprintf("stackshift-support-%s.zip", time.Now().Format("2006-01-02_15-04-05"))
+		}
+		f, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("creating support bundle: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	zw := zip.NewWriter(w)
+
+	redactor := newRedactor(redact)
+
+	if err := addLogsToZip(zw, redactor); err != nil {
+		return err
+	}
+
+	searchPath := resolveSearchPath()
+	repos := discoverRepositories(searchPath)
+
+	if err := addRepoStateToZip(zw, repos, redactor); err != nil {
+		return err
+	}
+
+	if err := addEnvironmentToZip(zw, redactor); err != nil {
+		return err
+	}
+
+	if err := addValidationToZip(zw, repos); err != nil {
+		return err
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("finalizing support bundle: %w", err)
+	}
+
+	if output != "-" {
+		fmt.Printf("Wrote support bundle to %s\n", output)
+	}
+	return nil
+}
+
+// addLogsToZip copies every *_gear*.log from the most recently modified run
+// under ~/.stackshift-results into logs/ in the bundle.
+func addLogsToZip(zw *zip.Writer, redactor redactor) error {
+	resultsDir, err := latestResultsDir()
+	if err != nil {
+		return nil // no prior runs to bundle; not fatal for a dump
+	}
+
+	entries, err := os.ReadDir(resultsDir)
+	if err != nil {
+		return nil
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.Contains(entry.Name(), "_gear") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(resultsDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+
+		w, err := zw.Create(filepath.Join("logs", entry.Name()))
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(redactor.bytes(data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// latestResultsDir returns the most recently modified run directory under
+// ~/.stackshift-results.
+func latestResultsDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	root := filepath.Join(homeDir, ".stackshift-results")
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return "", err
+	}
+
+	var latest string
+	var latestMod time.Time
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(latestMod) {
+			latestMod = info.ModTime()
+			latest = entry.Name()
+		}
+	}
+
+	if latest == "" {
+		return "", fmt.Errorf("no runs found under %s", root)
+	}
+	return filepath.Join(root, latest), nil
+}
+
+// addRepoStateToZip copies each discovered repo's .stackshift-state.json
+// into state/<repo-name>.json in the bundle.
+func addRepoStateToZip(zw *zip.Writer, repos []Repository, redactor redactor) error {
+	for _, repo := range repos {
+		data, err := os.ReadFile(filepath.Join(repo.Path, ".stackshift-state.json"))
+		if err != nil {
+			continue
+		}
+
+		w, err := zw.Create(filepath.Join("state", repo.Name+".json"))
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(redactor.bytes(data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backendVersion records one backend CLI's reported version, so a
+// maintainer can rule out a version mismatch without asking the reporter.
+type backendVersion struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// environmentDump is everything about the machine and configuration a
+// maintainer would otherwise have to ask the reporter for.
+type environmentDump struct {
+	GeneratedAt string           `json:"generatedAt"`
+	OS          string           `json:"os"`
+	Arch        string           `json:"arch"`
+	GoVersion   string           `json:"goVersion"`
+	Settings    Settings         `json:"settings"`
+	Backends    []backendVersion `json:"backends"`
+}
+
+// addEnvironmentToZip writes environment.json: OS/arch, the Go runtime
+// version, the effective Settings, and every registered backend CLI's
+// reported version (or why it couldn't be determined).
+func addEnvironmentToZip(zw *zip.Writer, redactor redactor) error {
+	// This subcommand never runs rootCmd's RunE, so layer config.toml and the
+	// default profile onto the flag vars the same way it would.
+	applyConfigDefaults(rootCmd.Flags())
+	if profile, err := resolveProfile(""); err == nil {
+		applyProfileDefaults(rootCmd.Flags(), profile)
+	}
+
+	dump := environmentDump{
+		GeneratedAt: time.Now().Format(time.RFC3339),
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+		GoVersion:   runtime.Version(),
+		Settings: Settings{
+			Route:          flagRoute,
+			Transmission:   flagTransmission,
+			Clarification:  flagClarification,
+			Implementation: flagImplementation,
+		},
+	}
+
+	names := make([]string, 0, len(backendRegistry))
+	for name := range backendRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// A backend's registry name isn't always the name of the CLI it runs.
+	binaries := map[string]string{"claude-code": "claude", "opencode": "code", "cursor": "cursor-agent"}
+	for _, name := range names {
+		binary := name
+		if b, ok := binaries[name]; ok {
+			binary = b
+		}
+		dump.Backends = append(dump.Backends, backendVersion{Name: name, Version: cliVersion(binary)})
+	}
+
+	data, err := json.MarshalIndent(dump, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	w, err := zw.Create("environment.json")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(redactor.bytes(data))
+	return err
+}
+
+// cliVersion runs "<name> --version" and returns its trimmed output, or a
+// short explanation if the CLI isn't installed or didn't run cleanly.
+func cliVersion(name string) string {
+	if _, err := exec.LookPath(name); err != nil {
+		return "not found in PATH"
+	}
+
+	out, err := exec.Command(name, "--version").CombinedOutput()
+	if err != nil {
+		return fmt.Sprintf("unavailable: %v", err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// gearValidation is one gear's pass/fail outcome for a single repo.
+type gearValidation struct {
+	Gear    string `json:"gear"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message"`
+}
+
+// repoValidation is every gear's validation outcome for one repo.
+type repoValidation struct {
+	Repo   string           `json:"repo"`
+	Checks []gearValidation `json:"checks"`
+}
+
+// addValidationToZip writes validation.json: for every discovered repo,
+// whether each gear's completion can be confirmed from its
+// .stackshift-state.json (see Orchestrator.validateGear).
+func addValidationToZip(zw *zip.Writer, repos []Repository) error {
+	var o Orchestrator
+
+	results := make([]repoValidation, 0, len(repos))
+	for _, repo := range repos {
+		rv := repoValidation{Repo: repo.Name}
+		for gear := 1; gear <= len(gearNames); gear++ {
+			passed, message, _ := o.validateGear(repo, gear)
+			rv.Checks = append(rv.Checks, gearValidation{
+				Gear:    gearNames[gear],
+				Passed:  passed,
+				Message: message,
+			})
+		}
+		results = append(results, rv)
+	}
+
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	w, err := zw.Create("validation.json")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// redactor strips absolute home paths and common repo-URL schemes from
+// bundle contents when --redact is set, so a support bundle can be shared
+// without leaking where the reporter's machine or repos live.
+type redactor struct {
+	enabled bool
+	homeDir string
+}
+
+var repoURLPattern = regexp.MustCompile(`(?:https?|git|ssh)://\S+|git@\S+`)
+
+func newRedactor(enabled bool) redactor {
+	homeDir, _ := os.UserHomeDir()
+	return redactor{enabled: enabled, homeDir: homeDir}
+}
+
+func (r redactor) bytes(data []byte) []byte {
+	if !r.enabled {
+		return data
+	}
+
+	text := string(data)
+	if r.homeDir != "" {
+		text = strings.ReplaceAll(text, r.homeDir, "~")
+	}
+	text = repoURLPattern.ReplaceAllString(text, "<redacted-repo-url>")
+	return []byte(text)
+}
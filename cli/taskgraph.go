@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TaskNode is one node in the gear dependency graph: a gear plus the gears
+// that must complete before it can run.
+type TaskNode struct {
+	Gear           string   `yaml:"gear"`
+	DependsOn      []string `yaml:"dependsOn"`
+	Parallelizable bool     `yaml:"parallelizable"`
+}
+
+// TaskGraph describes how gears depend on one another, replacing the old
+// hardcoded linear 1..6 gear list.
+type TaskGraph struct {
+	Nodes map[string]*TaskNode
+}
+
+// defaultTaskGraph mirrors the built-in gear pipeline, but lets create-specs
+// fan in from both analyze and reverse-engineer, and marks gap-analysis as
+// safe to run alongside other per-repo work.
+func defaultTaskGraph() *TaskGraph {
+	return &TaskGraph{Nodes: map[string]*TaskNode{
+		"analyze":          {Gear: "analyze"},
+		"reverse-engineer": {Gear: "reverse-engineer", DependsOn: []string{"analyze"}},
+		"create-specs":     {Gear: "create-specs", DependsOn: []string{"analyze", "reverse-engineer"}},
+		"gap-analysis":     {Gear: "gap-analysis", DependsOn: []string{"create-specs"}, Parallelizable: true},
+		"complete-spec":    {Gear: "complete-spec", DependsOn: []string{"gap-analysis"}},
+		"implement":        {Gear: "implement", DependsOn: []string{"complete-spec"}},
+	}}
+}
+
+// loadTaskGraph reads a --task-graph YAML override and merges its gears onto
+// the default graph, so users can insert custom gears (e.g. a
+// security-review gear between gap-analysis and complete-spec) without
+// forking the binary. An empty path returns the default graph unchanged.
+func loadTaskGraph(path string) (*TaskGraph, error) {
+	g := defaultTaskGraph()
+	if path == "" {
+		return g, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading task graph %s: %w", path, err)
+	}
+
+	var override struct {
+		Gears []TaskNode `yaml:"gears"`
+	}
+	if err := yaml.Unmarshal(data, &override); err != nil {
+		return nil, fmt.Errorf("parsing task graph %s: %w", path, err)
+	}
+
+	for _, n := range override.Gears {
+		node := n
+		g.Nodes[node.Gear] = &node
+	}
+
+	return g, nil
+}
+
+// withExclusions returns the subset of nodes not named in exclude. Edges
+// pointing at an excluded gear are dropped too, so a gear whose only
+// prerequisite got excluded becomes immediately ready.
+func (g *TaskGraph) withExclusions(exclude map[string]bool) map[string]*TaskNode {
+	nodes := make(map[string]*TaskNode, len(g.Nodes))
+	for name, node := range g.Nodes {
+		if exclude[name] {
+			continue
+		}
+
+		filtered := &TaskNode{Gear: node.Gear, Parallelizable: node.Parallelizable}
+		for _, dep := range node.DependsOn {
+			if !exclude[dep] {
+				filtered.DependsOn = append(filtered.DependsOn, dep)
+			}
+		}
+		nodes[name] = filtered
+	}
+	return nodes
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/jschulte/stackshift/pkg/detect"
+)
+
+// repoChangedMsg carries one repo's freshly recomputed state into the
+// Bubble Tea event loop, so the Select view can reflect gear progress
+// driven by a run happening outside this process.
+type repoChangedMsg struct {
+	repo Repository
+}
+
+// startRepoWatcher watches searchPath for new .git directories and each
+// already-discovered repo's .stackshift-state.json, sending a
+// repoChangedMsg whenever something changes. It runs until the process
+// exits; callers invoke it in its own goroutine.
+func startRepoWatcher(searchPath string, repos []Repository) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return
+	}
+	defer watcher.Close()
+
+	filepath.Walk(searchPath, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.IsDir() {
+			watcher.Add(path)
+		}
+		return nil
+	})
+
+	for _, r := range repos {
+		watcher.Add(r.Path)
+	}
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			handleWatchEvent(watcher, event)
+		case _, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// handleWatchEvent reacts to a single fsnotify event: a new .git directory
+// means a freshly cloned repo to add, a changed .stackshift-state.json
+// means an existing repo's gear progress moved.
+func handleWatchEvent(watcher *fsnotify.Watcher, event fsnotify.Event) {
+	if program == nil {
+		return
+	}
+
+	base := filepath.Base(event.Name)
+
+	switch {
+	case event.Op&fsnotify.Create != 0 && base != ".git" && base != ".stackshift-state.json":
+		// fsnotify isn't recursive, so a `git clone newrepo` — which creates
+		// newrepo/ before newrepo/.git — would otherwise only ever deliver
+		// this one Create event for newrepo/ itself, and the .git creation
+		// inside it would never be observed. Watch the new directory (and
+		// anything already inside it) so that follow-up event arrives.
+		addNewDirectory(watcher, event.Name)
+
+	case base == ".git" && event.Op&fsnotify.Create != 0:
+		repoPath := filepath.Dir(event.Name)
+		stack := detect.Detect(repoPath)
+		gear, status := checkProgress(repoPath)
+
+		watcher.Add(repoPath)
+		watcher.Add(filepath.Join(repoPath, ".stackshift-state.json"))
+
+		program.Send(repoChangedMsg{repo: Repository{
+			Name:           filepath.Base(repoPath),
+			Path:           repoPath,
+			Language:       stack.Language,
+			Framework:      stack.Framework,
+			PackageManager: stack.PackageManager,
+			Monorepo:       stack.Monorepo,
+			Workspaces:     stack.Workspaces,
+			CurrentGear:    gear,
+			Status:         status,
+		}})
+
+	case base == ".stackshift-state.json" && event.Op&(fsnotify.Write|fsnotify.Create) != 0:
+		repoPath := filepath.Dir(event.Name)
+		gear, status := checkProgress(repoPath)
+
+		program.Send(repoChangedMsg{repo: Repository{
+			Name:        filepath.Base(repoPath),
+			Path:        repoPath,
+			CurrentGear: gear,
+			Status:      status,
+		}})
+	}
+}
+
+// addNewDirectory adds path, and any preexisting subdirectories under it, to
+// watcher's watch set. It's a no-op if path isn't (or is no longer) a
+// directory.
+func addNewDirectory(watcher *fsnotify.Watcher, path string) {
+	info, err := os.Stat(path)
+	if err != nil || !info.IsDir() {
+		return
+	}
+
+	filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err == nil && info.IsDir() {
+			watcher.Add(p)
+		}
+		return nil
+	})
+}
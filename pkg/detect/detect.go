@@ -0,0 +1,347 @@
+// Package detect inspects a repository's working tree and infers its
+// language, framework, package manager, and (for monorepos) workspace
+// layout from the usual ecosystem manifest files.
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// TechStack is what Detect reports about a repository. Framework and
+// PackageManager are "" when they couldn't be inferred; Workspaces is only
+// populated when Monorepo is true.
+type TechStack struct {
+	Language       string
+	Framework      string
+	PackageManager string
+	Monorepo       bool
+	Workspaces     []string
+}
+
+// Detect inspects repoPath's manifest files and returns its best-guess
+// TechStack. Ecosystems are checked in a fixed priority order; the first
+// manifest found wins, matching how a developer would eyeball a repo.
+func Detect(repoPath string) TechStack {
+	for _, detector := range []func(string) (TechStack, bool){
+		detectNode,
+		detectPython,
+		detectGo,
+		detectRust,
+		detectDotNet,
+		detectJavaMaven,
+		detectJavaGradle,
+		detectRuby,
+		detectPHP,
+	} {
+		if stack, ok := detector(repoPath); ok {
+			return stack
+		}
+	}
+
+	return TechStack{Language: "Unknown"}
+}
+
+func readFile(repoPath, name string) (string, bool) {
+	data, err := os.ReadFile(filepath.Join(repoPath, name))
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+func exists(repoPath, name string) bool {
+	_, err := os.Stat(filepath.Join(repoPath, name))
+	return err == nil
+}
+
+// containsAny reports whether any of needles appears in haystack, case
+// sensitively. Manifest files are terse enough that a plain substring check
+// is as reliable as parsing them properly, and far less code.
+func containsAny(haystack string, needles ...string) bool {
+	for _, n := range needles {
+		if strings.Contains(haystack, n) {
+			return true
+		}
+	}
+	return false
+}
+
+var nodeFrameworks = []struct {
+	framework string
+	deps      []string
+}{
+	{"Next.js", []string{`"next"`}},
+	{"Nuxt", []string{`"nuxt"`}},
+	{"React", []string{`"react"`}},
+	{"Vue", []string{`"vue"`}},
+	{"Svelte", []string{`"svelte"`}},
+	{"Express", []string{`"express"`}},
+}
+
+func detectNode(repoPath string) (TechStack, bool) {
+	pkgJSON, ok := readFile(repoPath, "package.json")
+	if !ok {
+		return TechStack{}, false
+	}
+
+	stack := TechStack{Language: "JavaScript/TypeScript", PackageManager: "npm"}
+
+	switch {
+	case exists(repoPath, "pnpm-lock.yaml"):
+		stack.PackageManager = "pnpm"
+	case exists(repoPath, "yarn.lock"):
+		stack.PackageManager = "yarn"
+	case exists(repoPath, "package-lock.json"):
+		stack.PackageManager = "npm"
+	}
+
+	for _, candidate := range nodeFrameworks {
+		if containsAny(pkgJSON, candidate.deps...) {
+			stack.Framework = candidate.framework
+			break
+		}
+	}
+
+	if exists(repoPath, "pnpm-workspace.yaml") {
+		stack.Monorepo = true
+		stack.PackageManager = "pnpm"
+		stack.Workspaces = resolveWorkspaceGlobs(repoPath, pnpmWorkspacePackages(repoPath))
+	} else if patterns, ok := nodeWorkspacePatterns(pkgJSON); ok {
+		stack.Monorepo = true
+		stack.Workspaces = resolveWorkspaceGlobs(repoPath, patterns)
+	} else if exists(repoPath, "turbo.json") || exists(repoPath, "nx.json") {
+		stack.Monorepo = true
+	}
+
+	return stack, true
+}
+
+var nodeWorkspacesField = regexp.MustCompile(`"workspaces"\s*:\s*(\[[^\]]*\]|\{[^}]*"packages"\s*:\s*\[[^\]]*\])`)
+var quotedString = regexp.MustCompile(`"([^"]+)"`)
+
+// nodeWorkspacePatterns extracts package.json's "workspaces" field, which is
+// either a bare array of globs or an object with a "packages" array.
+func nodeWorkspacePatterns(pkgJSON string) ([]string, bool) {
+	match := nodeWorkspacesField.FindStringSubmatch(pkgJSON)
+	if match == nil {
+		return nil, false
+	}
+
+	var patterns []string
+	for _, m := range quotedString.FindAllStringSubmatch(match[1], -1) {
+		patterns = append(patterns, m[1])
+	}
+	if len(patterns) == 0 {
+		return nil, false
+	}
+	return patterns, true
+}
+
+func pnpmWorkspacePackages(repoPath string) []string {
+	data, ok := readFile(repoPath, "pnpm-workspace.yaml")
+	if !ok {
+		return nil
+	}
+
+	var patterns []string
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "- ") {
+			patterns = append(patterns, strings.Trim(strings.TrimPrefix(line, "- "), `'"`))
+		}
+	}
+	return patterns
+}
+
+// resolveWorkspaceGlobs descends one level into each "dir/*" pattern and
+// lists its member directories; a pattern with no trailing "/*" is kept
+// as-is since it already names a single workspace member.
+func resolveWorkspaceGlobs(repoPath string, patterns []string) []string {
+	var members []string
+	for _, pattern := range patterns {
+		if !strings.HasSuffix(pattern, "/*") {
+			members = append(members, pattern)
+			continue
+		}
+
+		dir := filepath.Join(repoPath, strings.TrimSuffix(pattern, "/*"))
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if e.IsDir() {
+				members = append(members, filepath.Join(strings.TrimSuffix(pattern, "/*"), e.Name()))
+			}
+		}
+	}
+	return members
+}
+
+func detectPython(repoPath string) (TechStack, bool) {
+	var manifest string
+	stack := TechStack{Language: "Python", PackageManager: "pip"}
+
+	if data, ok := readFile(repoPath, "pyproject.toml"); ok {
+		manifest = data
+		if containsAny(data, "[tool.poetry]") {
+			stack.PackageManager = "poetry"
+		}
+	} else if data, ok := readFile(repoPath, "requirements.txt"); ok {
+		manifest = data
+	} else {
+		return TechStack{}, false
+	}
+
+	switch {
+	case containsAny(manifest, "django", "Django"):
+		stack.Framework = "Django"
+	case containsAny(manifest, "fastapi"):
+		stack.Framework = "FastAPI"
+	case containsAny(manifest, "flask", "Flask"):
+		stack.Framework = "Flask"
+	}
+
+	return stack, true
+}
+
+func detectGo(repoPath string) (TechStack, bool) {
+	modFile, ok := readFile(repoPath, "go.mod")
+	if !ok {
+		return TechStack{}, false
+	}
+
+	stack := TechStack{Language: "Go", PackageManager: "go modules"}
+
+	switch {
+	case containsAny(modFile, "github.com/gin-gonic/gin"):
+		stack.Framework = "Gin"
+	case containsAny(modFile, "github.com/labstack/echo"):
+		stack.Framework = "Echo"
+	case containsAny(modFile, "github.com/go-chi/chi"):
+		stack.Framework = "Chi"
+	case containsAny(modFile, "github.com/spf13/cobra"):
+		stack.Framework = "Cobra"
+	}
+
+	return stack, true
+}
+
+var cargoMembers = regexp.MustCompile(`members\s*=\s*\[([^\]]*)\]`)
+
+func detectRust(repoPath string) (TechStack, bool) {
+	cargoToml, ok := readFile(repoPath, "Cargo.toml")
+	if !ok {
+		return TechStack{}, false
+	}
+
+	stack := TechStack{Language: "Rust", PackageManager: "cargo"}
+
+	switch {
+	case containsAny(cargoToml, "tauri ="):
+		stack.Framework = "Tauri"
+	case containsAny(cargoToml, "axum ="):
+		stack.Framework = "Axum"
+	case containsAny(cargoToml, "actix-web ="):
+		stack.Framework = "Actix"
+	case containsAny(cargoToml, "leptos ="):
+		stack.Framework = "Leptos"
+	}
+
+	if containsAny(cargoToml, "[workspace]") {
+		stack.Monorepo = true
+		if match := cargoMembers.FindStringSubmatch(cargoToml); match != nil {
+			var members []string
+			for _, m := range quotedString.FindAllStringSubmatch(match[1], -1) {
+				members = append(members, m[1])
+			}
+			stack.Workspaces = resolveWorkspaceGlobs(repoPath, members)
+		}
+	}
+
+	return stack, true
+}
+
+func detectDotNet(repoPath string) (TechStack, bool) {
+	entries, err := os.ReadDir(repoPath)
+	if err != nil {
+		return TechStack{}, false
+	}
+
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".csproj") {
+			stack := TechStack{Language: "C#", PackageManager: "nuget"}
+			if data, ok := readFile(repoPath, e.Name()); ok && containsAny(data, "Microsoft.AspNetCore") {
+				stack.Framework = "ASP.NET Core"
+			}
+			return stack, true
+		}
+	}
+
+	return TechStack{}, false
+}
+
+func detectJavaMaven(repoPath string) (TechStack, bool) {
+	pom, ok := readFile(repoPath, "pom.xml")
+	if !ok {
+		return TechStack{}, false
+	}
+
+	stack := TechStack{Language: "Java", PackageManager: "maven"}
+	if containsAny(pom, "spring-boot") {
+		stack.Framework = "Spring Boot"
+	}
+	return stack, true
+}
+
+func detectJavaGradle(repoPath string) (TechStack, bool) {
+	var gradle string
+	if data, ok := readFile(repoPath, "build.gradle"); ok {
+		gradle = data
+	} else if data, ok := readFile(repoPath, "build.gradle.kts"); ok {
+		gradle = data
+	} else {
+		return TechStack{}, false
+	}
+
+	stack := TechStack{Language: "Java", PackageManager: "gradle"}
+	if containsAny(gradle, "org.springframework.boot") {
+		stack.Framework = "Spring Boot"
+	}
+	return stack, true
+}
+
+func detectRuby(repoPath string) (TechStack, bool) {
+	gemfile, ok := readFile(repoPath, "Gemfile")
+	if !ok {
+		return TechStack{}, false
+	}
+
+	stack := TechStack{Language: "Ruby", PackageManager: "bundler"}
+	switch {
+	case containsAny(gemfile, `"rails"`, `'rails'`):
+		stack.Framework = "Rails"
+	case containsAny(gemfile, `"sinatra"`, `'sinatra'`):
+		stack.Framework = "Sinatra"
+	}
+	return stack, true
+}
+
+func detectPHP(repoPath string) (TechStack, bool) {
+	composer, ok := readFile(repoPath, "composer.json")
+	if !ok {
+		return TechStack{}, false
+	}
+
+	stack := TechStack{Language: "PHP", PackageManager: "composer"}
+	switch {
+	case containsAny(composer, "laravel/framework"):
+		stack.Framework = "Laravel"
+	case containsAny(composer, `"symfony/`):
+		stack.Framework = "Symfony"
+	}
+	return stack, true
+}